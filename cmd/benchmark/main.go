@@ -1,14 +1,28 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math/rand"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ExonegeS/mechta-two-weeks/pkg/grpc"
 )
 
 type Payload struct {
@@ -22,24 +36,253 @@ type Item struct {
 
 var (
 	payloadSize = flag.Int("s", 1000, "Number of items in payload")
+	mode        = flag.String("mode", "gen", "gen: write the sample file, http: load /data/{id}, grpc: load GetFinalPriceInfo")
+	sampleFile  = flag.String("file", "samples/large_payload.json", "Sample payload file")
+
+	httpURL       = flag.String("url", "http://localhost:8080", "HTTP server base URL")
+	grpcAddr      = flag.String("grpc", "localhost:50051", "gRPC server address")
+	subdivisionID = flag.String("id", "1", "Subdivision ID to request")
+	token         = flag.String("token", "", "API key or JWT sent as a Bearer credential, for servers with AUTH_ENABLED")
+
+	concurrency = flag.Int("c", 1, "Number of concurrent clients")
+	requests    = flag.Int("n", 10, "Total number of requests")
+	timeout     = flag.Duration("timeout", 5*time.Minute, "Timeout of a single request")
+	target      = flag.Float64("target", 1_000_000, "Required processed items per hour, 0 to skip the check")
 )
 
 type Metrics struct {
+	mu sync.Mutex
+
 	Total       int64
 	Success     int64
 	Failed      int64
 	TotalTime   time.Duration
-	StatusCodes map[int]int64
+	StatusCodes map[string]int64
+	Latencies   []time.Duration
+
+	ItemsProcessed int64
+	ItemsFailed    int64
+}
+
+type result struct {
+	status    string
+	err       error
+	latency   time.Duration
+	processed int64
+	failed    int64
+}
+
+func (m *Metrics) record(r result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Total++
+	if r.err != nil {
+		m.Failed++
+	} else {
+		m.Success++
+	}
+	m.StatusCodes[r.status]++
+	m.Latencies = append(m.Latencies, r.latency)
+	m.ItemsProcessed += r.processed
+	m.ItemsFailed += r.failed
 }
 
 func main() {
 	flag.Parse()
 
-	sampleFile := "samples/large_payload.json"
-	if err := generateSampleFile(sampleFile, *payloadSize); err != nil {
-		log.Fatalf("Failed to generate sample file: %v", err)
+	if *mode == "gen" {
+		if err := generateSampleFile(*sampleFile, *payloadSize); err != nil {
+			log.Fatalf("Failed to generate sample file: %v", err)
+		}
+		fmt.Printf("Generated sample file: %s (%d)\n", *sampleFile, *payloadSize)
+		return
+	}
+
+	payload, err := loadPayload(*sampleFile, *payloadSize)
+	if err != nil {
+		log.Fatalf("Failed to load payload: %v", err)
+	}
+
+	var send func(ctx context.Context) result
+	switch *mode {
+	case "http":
+		send, err = httpSender(payload)
+	case "grpc":
+		send, err = grpcSender(payload)
+	default:
+		err = fmt.Errorf("unknown mode %q", *mode)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Sending %d requests of %d items with %d clients (%s)\n", *requests, len(payload.Items), *concurrency, *mode)
+	metrics := run(send)
+	report(metrics)
+}
+
+func run(send func(ctx context.Context) result) *Metrics {
+	metrics := &Metrics{StatusCodes: make(map[string]int64)}
+
+	queue := make(chan struct{}, *requests)
+	for i := 0; i < *requests; i++ {
+		queue <- struct{}{}
+	}
+	close(queue)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < max(*concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range queue {
+				ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+				metrics.record(send(ctx))
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	metrics.TotalTime = time.Since(start)
+	return metrics
+}
+
+func httpSender(payload Payload) (func(ctx context.Context) result, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/data/%s", *httpURL, *subdivisionID)
+	client := &http.Client{}
+
+	return func(ctx context.Context) result {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, bytes.NewReader(body))
+		if err != nil {
+			return result{status: "error", err: err}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if *token != "" {
+			req.Header.Set("Authorization", "Bearer "+*token)
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return result{status: "error", err: err, latency: time.Since(start)}
+		}
+		defer resp.Body.Close()
+
+		var data struct {
+			TotalProcessed int64 `json:"total_processed"`
+			TotalFailed    int64 `json:"total_failed"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		io.Copy(io.Discard, resp.Body)
+		r := result{
+			status:    fmt.Sprint(resp.StatusCode),
+			latency:   time.Since(start),
+			processed: data.TotalProcessed,
+			failed:    data.TotalFailed,
+		}
+		if resp.StatusCode != http.StatusOK {
+			r.err = fmt.Errorf("status %d", resp.StatusCode)
+		} else if err != nil {
+			r.err = err
+		}
+		return r
+	}, nil
+}
+
+func grpcSender(payload Payload) (func(ctx context.Context) result, error) {
+	conn, err := grpc.NewClient(*grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	client := pb.NewMindboxServiceClient(conn)
+
+	req := &pb.GetFinalPriceInfoRequest{
+		Id:    *subdivisionID,
+		Items: make([]*pb.Item, len(payload.Items)),
+	}
+	for i, item := range payload.Items {
+		req.Items[i] = &pb.Item{ProductId: item.ProductID, Price: item.Price}
+	}
+
+	return func(ctx context.Context) result {
+		if *token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+		}
+		start := time.Now()
+		resp, err := client.GetFinalPriceInfo(ctx, req, grpc.MaxCallRecvMsgSize(1<<30))
+		return result{
+			status:    status.Code(err).String(),
+			err:       err,
+			latency:   time.Since(start),
+			processed: int64(resp.GetTotalProcessed()),
+			failed:    int64(resp.GetTotalFailed()),
+		}
+	}, nil
+}
+
+func report(m *Metrics) {
+	sort.Slice(m.Latencies, func(i, j int) bool { return m.Latencies[i] < m.Latencies[j] })
+
+	seconds := m.TotalTime.Seconds()
+	itemsPerHour := float64(m.ItemsProcessed) / seconds * 3600
+
+	fmt.Printf("\nRequests:     %d total, %d ok, %d failed\n", m.Total, m.Success, m.Failed)
+	fmt.Printf("Duration:     %s\n", m.TotalTime.Round(time.Millisecond))
+	fmt.Printf("Throughput:   %.2f req/s, %.2f items/s (%.0f items/hour)\n",
+		float64(m.Total)/seconds, float64(m.ItemsProcessed)/seconds, itemsPerHour)
+	fmt.Printf("Latency:      p50 %s, p95 %s, p99 %s, max %s\n",
+		percentile(m.Latencies, 50), percentile(m.Latencies, 95), percentile(m.Latencies, 99), percentile(m.Latencies, 100))
+	fmt.Printf("Items:        %d processed, %d failed\n", m.ItemsProcessed, m.ItemsFailed)
+
+	codes := make([]string, 0, len(m.StatusCodes))
+	for code := range m.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	fmt.Printf("Status codes:")
+	for _, code := range codes {
+		fmt.Printf(" %s=%d", code, m.StatusCodes[code])
+	}
+	fmt.Println()
+
+	if *target > 0 {
+		if itemsPerHour < *target {
+			fmt.Printf("\nFAIL: %.0f items/hour is below the target of %.0f\n", itemsPerHour, *target)
+			os.Exit(1)
+		}
+		fmt.Printf("\nOK: %.0f items/hour meets the target of %.0f\n", itemsPerHour, *target)
+	}
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p + 99) / 100
+	return sorted[min(max(i-1, 0), len(sorted)-1)].Round(time.Millisecond)
+}
+
+// loadPayload reads the sample file, falling back to a generated payload of
+// size items when the file does not exist.
+func loadPayload(filename string, size int) (Payload, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return generatePayload(size), nil
+	}
+	if err != nil {
+		return Payload{}, err
+	}
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return Payload{}, fmt.Errorf("failed to parse %s: %w", filename, err)
 	}
-	fmt.Printf("Generated sample file: %s (%d)\n", sampleFile, *payloadSize)
+	return payload, nil
 }
 
 func generatePayload(size int) Payload {
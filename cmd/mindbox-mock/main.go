@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/mindbox/mindboxmock"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+var (
+	addr       = flag.String("addr", ":8081", "Listen address")
+	configFile = flag.String("config", "", "JSON file with prices, promotions, placeholders and export promotions")
+	secretKey  = flag.String("secret", "", "Required Mindbox secret key, empty to accept any")
+
+	discount = flag.Float64("discount", 0.1, "Discount applied to products without a fixed price")
+	reject   = flag.String("reject", "", "Comma-separated product IDs that make their batch fail")
+
+	latency     = flag.Duration("latency", 0, "Delay before every response")
+	jitter      = flag.Duration("jitter", 0, "Random extra delay up to this value")
+	errorRate   = flag.Float64("error-rate", 0, "Share of requests answered with -error-status")
+	errorStatus = flag.Int("error-status", http.StatusInternalServerError, "HTTP status for injected errors")
+	status      = flag.String("status", "", "Override the status of GetProductInfo responses")
+	processing  = flag.String("processing-status", "", "Override the processingStatus of GetProductInfo responses")
+	exportPolls = flag.Int("export-polls", 1, "Status checks that report NotReady before an export is Ready")
+)
+
+func main() {
+	flag.Parse()
+
+	// Flag defaults are overridden by the config file, and the file by the
+	// flags set on the command line.
+	cfg := mindboxmock.Config{}
+	flag.VisitAll(func(f *flag.Flag) { applyFlag(&cfg, f.Name) })
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			log.Fatalf("Failed to read config: %v", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			log.Fatalf("Failed to parse config: %v", err)
+		}
+	}
+	if len(cfg.Promotions) == 0 && len(cfg.ExportPromotions) == 0 {
+		setDefaultPromotions(&cfg)
+	}
+
+	flag.Visit(func(f *flag.Flag) { applyFlag(&cfg, f.Name) })
+
+	fmt.Printf("Mindbox mock listening on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, mindboxmock.New(cfg)); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+func applyFlag(cfg *mindboxmock.Config, name string) {
+	switch name {
+	case "secret":
+		cfg.SecretKey = *secretKey
+	case "discount":
+		cfg.Discount = *discount
+	case "reject":
+		if *reject != "" {
+			cfg.RejectProducts = strings.Split(*reject, ",")
+		}
+	case "latency":
+		cfg.Latency = *latency
+	case "jitter":
+		cfg.LatencyJitter = *jitter
+	case "error-rate":
+		cfg.ErrorRate = *errorRate
+	case "error-status":
+		cfg.ErrorStatus = *errorStatus
+	case "status":
+		cfg.Status = *status
+	case "processing-status":
+		cfg.ProcessingStatus = *processing
+	case "export-polls":
+		cfg.ExportPolls = *exportPolls
+	}
+}
+
+func setDefaultPromotions(cfg *mindboxmock.Config) {
+	promo := mindboxmock.Promotion{
+		MindboxId:  1,
+		ExternalId: "promo-1",
+		Name:       "Mock discount",
+		Type:       "discount",
+	}
+	cfg.Promotions = []mindboxmock.Promotion{promo}
+	cfg.Placeholders = []mindboxmock.Placeholder{{
+		ExternalId: "placeholder-1",
+		Type:       "text",
+		Message:    "Mock discount applied",
+		Promotion:  promo,
+	}}
+
+	start := "2025-01-01T00:00:00"
+	exported := domain.PromotionSt{
+		Name:             promo.Name,
+		StartDateTimeUtc: &start,
+		State:            "Active",
+	}
+	exported.Ids.ExternalID = promo.ExternalId
+	exported.CustomFields.ShemaV1C = "mock-schema"
+	cfg.ExportPromotions = []domain.PromotionSt{exported}
+}
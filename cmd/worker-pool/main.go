@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/ExonegeS/mechta-two-weeks/config"
 	"github.com/ExonegeS/mechta-two-weeks/internal/app"
@@ -12,6 +16,13 @@ func main() {
 	cfg := config.NewConfig(".env")
 	logger := prettyslog.SetupPrettySlog(os.Stdout)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	server := app.NewAPIServer(cfg, logger)
-	server.Run()
+	if err := server.Run(ctx); err != nil {
+		logger.Error("server stopped with error", slog.String("error", err.Error()))
+		stop()
+		os.Exit(1)
+	}
 }
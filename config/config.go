@@ -13,24 +13,110 @@ type (
 	Config struct {
 		Server          Server
 		WorkerConfig    WorkerConfig
+		JobConfig       JobConfig
+		Storage         Storage
 		ExternalService ExternalService
+		Health          Health
+		Cache           Cache
+		Scheduler       Scheduler
+		Catalog         PromotionCatalog
+		Validation      Validation
+		Auth            Auth
 	}
 
 	Server struct {
 		Address  string
 		Port     string
 		GRPCPort string
+
+		ShutdownGracePeriod time.Duration
 	}
 
 	WorkerConfig struct {
 		MaxWorkers int64
 		BatchSize  int64
+
+		BatchSizeMin       int64
+		BatchSizeMax       int64
+		BatchLatencyTarget time.Duration
+
+		BisectEnabled bool
+		BisectMinSize int64
+	}
+
+	JobConfig struct {
+		Runners   int64
+		QueueSize int64
+		Timeout   time.Duration
+		TTL       time.Duration
+	}
+
+	Storage struct {
+		QueueDir string
+		// SnapshotFile keeps the results delta runs compare against. When
+		// empty, they are kept in memory only.
+		SnapshotFile string
 	}
 
 	ExternalService struct {
 		URI       string
 		Timeout   time.Duration
 		SecretKey string
+
+		RequestsPerSecond float64
+		ItemsPerSecond    float64
+	}
+
+	Health struct {
+		ProbeInterval time.Duration
+		ProbeTimeout  time.Duration
+	}
+
+	Cache struct {
+		Enabled            bool
+		TTL                time.Duration
+		File               string
+		PromotionsInterval time.Duration
+	}
+
+	// PromotionCatalog completes applied promotions with the data of the
+	// promotions export, read every RefreshInterval.
+	PromotionCatalog struct {
+		Enabled         bool
+		RefreshInterval time.Duration
+	}
+
+	// Validation rules for the products of a run. An empty IdPattern only
+	// rules out empty IDs, an empty MinPrice or MaxPrice leaves that end of
+	// the range open. Dedup is first, last or error.
+	Validation struct {
+		MaxItems  int64
+		IdPattern string
+		MinPrice  string
+		MaxPrice  string
+		Dedup     string
+	}
+
+	// Auth guards the HTTP and gRPC entry points. APIKeys is a
+	// comma-separated list of name:key:scopes entries with scopes separated
+	// by |; tokens are HMAC-signed JWTs verified with JWTSecret. Either may
+	// be left empty.
+	Auth struct {
+		Enabled     bool
+		APIKeys     string
+		JWTSecret   string
+		JWTIssuer   string
+		JWTAudience string
+		JWTLeeway   time.Duration
+	}
+
+	// Scheduler runs the schedules defined in File, a JSON file. Without a
+	// file no schedules run. A PromotionsInterval of 0 disables the
+	// promotions refresh.
+	Scheduler struct {
+		File               string
+		SourceTimeout      time.Duration
+		PromotionsInterval time.Duration
 	}
 )
 
@@ -45,15 +131,72 @@ func NewConfig(filename string) *Config {
 			Address:  getEnvStr("ADDRESS", ""),
 			Port:     getEnvStr("PORT", "8080"),
 			GRPCPort: getEnvStr("GRPC_PORT", "50051"),
+
+			ShutdownGracePeriod: time.Duration(getEnvInt64("SHUTDOWN_GRACE_PERIOD", 30)) * time.Second,
 		},
 		WorkerConfig{
 			MaxWorkers: getEnvInt64("MAX_WORKERS", 3),
 			BatchSize:  getEnvInt64("BATCH_SIZE", 3000),
+
+			BatchSizeMin:       getEnvInt64("BATCH_SIZE_MIN", 100),
+			BatchSizeMax:       getEnvInt64("BATCH_SIZE_MAX", 5000),
+			BatchLatencyTarget: time.Duration(getEnvInt64("BATCH_LATENCY_TARGET_MS", 5000)) * time.Millisecond,
+
+			BisectEnabled: getEnvBool("BISECT_ENABLED", false),
+			BisectMinSize: getEnvInt64("BISECT_MIN_SIZE", 1),
+		},
+		JobConfig{
+			Runners:   getEnvInt64("JOB_RUNNERS", 1),
+			QueueSize: getEnvInt64("JOB_QUEUE_SIZE", 100),
+			Timeout:   time.Duration(getEnvInt64("JOB_TIMEOUT", 7200)) * time.Second,
+			TTL:       time.Duration(getEnvInt64("JOB_TTL", 3600)) * time.Second,
+		},
+		Storage{
+			QueueDir:     getEnvStr("QUEUE_DIR", "data/queue"),
+			SnapshotFile: getEnvStr("SNAPSHOT_FILE", "data/snapshots.jsonl"),
 		},
 		ExternalService{
 			URI:       mustEnvStr("URI"),
 			Timeout:   time.Duration(getEnvInt64("SERVER_TIMEOUT", 120)) * time.Second,
 			SecretKey: mustEnvStr("SECRET_KEY"),
+
+			RequestsPerSecond: getEnvFloat64("RATE_LIMIT_RPS", 5),
+			// 1M items per hour.
+			ItemsPerSecond: getEnvFloat64("RATE_LIMIT_ITEMS_PER_SEC", 278),
+		},
+		Health{
+			ProbeInterval: time.Duration(getEnvInt64("HEALTH_PROBE_INTERVAL", 10)) * time.Second,
+			ProbeTimeout:  time.Duration(getEnvInt64("HEALTH_PROBE_TIMEOUT", 3)) * time.Second,
+		},
+		Cache{
+			Enabled:            getEnvBool("CACHE_ENABLED", false),
+			TTL:                time.Duration(getEnvInt64("CACHE_TTL", 600)) * time.Second,
+			File:               getEnvStr("CACHE_FILE", ""),
+			PromotionsInterval: time.Duration(getEnvInt64("CACHE_PROMOTIONS_INTERVAL", 300)) * time.Second,
+		},
+		Scheduler{
+			File:               getEnvStr("SCHEDULES_FILE", ""),
+			SourceTimeout:      time.Duration(getEnvInt64("SCHEDULE_SOURCE_TIMEOUT", 60)) * time.Second,
+			PromotionsInterval: time.Duration(getEnvInt64("SCHEDULER_PROMOTIONS_INTERVAL", 0)) * time.Second,
+		},
+		PromotionCatalog{
+			Enabled:         getEnvBool("PROMOTION_CATALOG_ENABLED", true),
+			RefreshInterval: time.Duration(getEnvInt64("PROMOTION_CATALOG_INTERVAL", 300)) * time.Second,
+		},
+		Validation{
+			MaxItems:  getEnvInt64("VALIDATION_MAX_ITEMS", 1000000),
+			IdPattern: getEnvStr("VALIDATION_ID_PATTERN", ""),
+			MinPrice:  getEnvStr("VALIDATION_MIN_PRICE", "0"),
+			MaxPrice:  getEnvStr("VALIDATION_MAX_PRICE", ""),
+			Dedup:     getEnvStr("VALIDATION_DEDUP", "first"),
+		},
+		Auth{
+			Enabled:     getEnvBool("AUTH_ENABLED", false),
+			APIKeys:     getEnvStr("AUTH_API_KEYS", ""),
+			JWTSecret:   getEnvStr("AUTH_JWT_SECRET", ""),
+			JWTIssuer:   getEnvStr("AUTH_JWT_ISSUER", ""),
+			JWTAudience: getEnvStr("AUTH_JWT_AUDIENCE", ""),
+			JWTLeeway:   time.Duration(getEnvInt64("AUTH_JWT_LEEWAY", 30)) * time.Second,
 		},
 	}
 }
@@ -85,3 +228,29 @@ func getEnvInt64(key string, fallback int64) int64 {
 
 	return fallback
 }
+
+func getEnvFloat64(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fallback
+		}
+
+		return f
+	}
+
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fallback
+		}
+
+		return b
+	}
+
+	return fallback
+}
@@ -0,0 +1,213 @@
+package filequeue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+const fileExt = ".jsonl"
+
+const (
+	recordRun   = "run"
+	recordBatch = "batch"
+	recordDone  = "done"
+)
+
+// Queue keeps one append-only JSON lines journal per run. Every record is
+// synced to disk before the call returns, and a journal is deleted once its
+// run is finished.
+type Queue struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+type record struct {
+	Kind  string            `json:"kind"`
+	Run   *domain.SyncRun   `json:"run,omitempty"`
+	Batch *domain.SyncBatch `json:"batch,omitempty"`
+}
+
+func New(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return &Queue{
+		dir:   dir,
+		files: make(map[string]*os.File),
+	}, nil
+}
+
+func (q *Queue) Begin(run *domain.SyncRun) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(q.path(run.Id), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	q.files[run.Id] = f
+
+	header := *run
+	header.Batches = nil
+	return q.append(run.Id, record{Kind: recordRun, Run: &header})
+}
+
+func (q *Queue) Enqueue(runId string, batch *domain.SyncBatch) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := *batch
+	b.Outcome = nil
+	return q.append(runId, record{Kind: recordBatch, Batch: &b})
+}
+
+func (q *Queue) Complete(runId string, batch *domain.SyncBatch) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.append(runId, record{Kind: recordDone, Batch: batch})
+}
+
+func (q *Queue) Finish(runId string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if f, ok := q.files[runId]; ok {
+		f.Close()
+		delete(q.files, runId)
+	}
+	if err := os.Remove(q.path(runId)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Unfinished reads every journal in the directory. A journal that ends with
+// a partially written line is read up to that line.
+func (q *Queue) Unfinished() ([]*domain.SyncRun, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*domain.SyncRun
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		path := filepath.Join(q.dir, e.Name())
+		run, size, err := q.load(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Name(), err)
+		}
+		if run == nil {
+			os.Remove(path)
+			continue
+		}
+
+		q.mu.Lock()
+		if _, ok := q.files[run.Id]; !ok {
+			// Drop a torn trailing record, so new records start on a
+			// fresh line.
+			if err := os.Truncate(path, size); err != nil {
+				q.mu.Unlock()
+				return nil, err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				q.mu.Unlock()
+				return nil, err
+			}
+			q.files[run.Id] = f
+		}
+		q.mu.Unlock()
+
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// load returns the run stored in the journal at path and the size of its
+// readable prefix.
+func (q *Queue) load(path string) (*domain.SyncRun, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var (
+		run     *domain.SyncRun
+		size    int64
+		batches = make(map[int]*domain.SyncBatch)
+	)
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, 0, err
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			break
+		}
+		size += int64(len(line))
+
+		switch rec.Kind {
+		case recordRun:
+			run = rec.Run
+		case recordBatch, recordDone:
+			if rec.Batch != nil {
+				batches[rec.Batch.Seq] = rec.Batch
+			}
+		}
+	}
+	if run == nil {
+		return nil, 0, nil
+	}
+
+	for _, b := range batches {
+		run.Batches = append(run.Batches, b)
+	}
+	sort.Slice(run.Batches, func(i, j int) bool {
+		return run.Batches[i].Seq < run.Batches[j].Seq
+	})
+	return run, size, nil
+}
+
+func (q *Queue) append(runId string, rec record) error {
+	f, ok := q.files[runId]
+	if !ok {
+		return fmt.Errorf("run %s is not open", runId)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func (q *Queue) path(runId string) string {
+	return filepath.Join(q.dir, runId+fileExt)
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	context "context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/ExonegeS/mechta-two-weeks/pkg/grpc"
+)
+
+// methodScopes are the scopes MindboxService methods require. Other
+// services, like health checks and reflection, are public.
+var methodScopes = map[string]domain.Scope{
+	pb.MindboxService_GetFinalPriceInfo_FullMethodName:    domain.ScopeData,
+	pb.MindboxService_UploadFinalPriceInfo_FullMethodName: domain.ScopeData,
+	pb.MindboxService_StreamFinalPriceInfo_FullMethodName: domain.ScopeData,
+	pb.MindboxService_SyncFinalPriceInfo_FullMethodName:   domain.ScopeData,
+	pb.MindboxService_GetPromotionsInfo_FullMethodName:    domain.ScopePromotions,
+}
+
+// NewAuthInterceptors check the credential of every MindboxService call,
+// read from the "authorization: Bearer" or "x-api-key" metadata. A nil
+// Authenticator lets every call through.
+func NewAuthInterceptors(logger *slog.Logger, auth service.Authenticator) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	authorize := func(ctx context.Context, method string) (context.Context, error) {
+		scope, ok := methodScopes[method]
+		if !ok || auth == nil {
+			return ctx, nil
+		}
+		principal, err := service.Authorize(auth, credential(ctx), scope)
+		if err != nil {
+			logger.Warn("call rejected", slog.String("method", method), slog.String("error", err.Error()))
+			if errors.Is(err, service.ErrForbidden) {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+			return nil, status.Error(codes.Unauthenticated, service.ErrUnauthenticated.Error())
+		}
+		return service.ContextWithPrincipal(ctx, principal), nil
+	}
+
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+	}
+	return unary, stream
+}
+
+// authorizedStream carries the caller in its context.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func credential(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if keys := md.Get("x-api-key"); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
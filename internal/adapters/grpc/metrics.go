@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	context "context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// NewMetricsInterceptors record the duration of every RPC by method and
+// status code.
+func NewMetricsInterceptors(reg prometheus.Registerer) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Duration of gRPC calls by method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+	reg.MustRegister(duration)
+
+	observe := func(method string, start time.Time, err error) {
+		duration.WithLabelValues(method, status.Code(err).String()).
+			Observe(time.Since(start).Seconds())
+	}
+
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, start, err)
+		return resp, err
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(info.FullMethod, start, err)
+		return err
+	}
+	return unary, stream
+}
@@ -2,15 +2,19 @@ package grpc
 
 import (
 	context "context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"time"
 
 	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
 	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/ExonegeS/mechta-two-weeks/pkg/grpc"
@@ -29,19 +33,44 @@ func NewMindboxServer(logger *slog.Logger, service *service.SyncService) *Mindbo
 	}
 }
 
-func StartGRPCServer(grpcPort string, syncService *service.SyncService, logger *slog.Logger) error {
-	lis, err := net.Listen("tcp", ":"+grpcPort)
-	if err != nil {
-		return fmt.Errorf("failed to listen on port %s: %w", grpcPort, err)
-	}
-
-	grpcServer := grpc.NewServer()
+// NewGRPCServer registers MindboxService and grpc.health.v1. Both the
+// overall status and the MindboxService status follow healthService
+// readiness.
+func NewGRPCServer(
+	syncService *service.SyncService,
+	healthService *service.HealthService,
+	logger *slog.Logger,
+	opts ...grpc.ServerOption,
+) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
 	invServer := NewMindboxServer(logger, syncService)
 	pb.RegisterMindboxServiceServer(grpcServer, invServer)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthService.Watch(func(ready bool) {
+		servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready {
+			servingStatus = healthpb.HealthCheckResponse_SERVING
+		}
+		healthServer.SetServingStatus("", servingStatus)
+		healthServer.SetServingStatus(pb.MindboxService_ServiceDesc.ServiceName, servingStatus)
+	})
 	reflection.Register(grpcServer)
 
-	logger.Info("gRPC server listening", "port", grpcPort)
-	return grpcServer.Serve(lis)
+	return grpcServer
+}
+
+// serviceError reports a SyncService failure to the client. Rejections
+// during shutdown are Unavailable, so clients know to retry elsewhere.
+func serviceError(err error) error {
+	if errors.Is(err, service.ErrDraining) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, service.ErrTooManyItems) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return fmt.Errorf("failed to get final price info: %w", err)
 }
 
 func (s *MindboxServer) GetFinalPriceInfo(ctx context.Context, req *pb.GetFinalPriceInfoRequest) (*pb.GetFinalPriceInfoResponse, error) {
@@ -53,28 +82,84 @@ func (s *MindboxServer) GetFinalPriceInfo(ctx context.Context, req *pb.GetFinalP
 		return nil, fmt.Errorf("no products provided")
 	}
 
-	parsedProducts := make([]*domain.BasePrice, len(products))
-	for i, product := range products {
-		parsedProducts[i] = &domain.BasePrice{
-			ProductId: product.ProductId,
-			Price:     product.GetPrice(),
-		}
-	}
 	start := time.Now()
-	processed, failed, err := s.service.GetData(ctx, req.GetId(), time.Now(), parsedProducts)
+	parsedProducts, rejected := parseProducts(products)
+	result, err := s.service.GetData(ctx, req.GetId(), time.Now(), parsedProducts,
+		append(runOptions(req), service.WithRejected(rejected...))...)
 	if err != nil {
 		s.logger.Error("Error processing request", "error", err)
-		return nil, fmt.Errorf("failed to get final price info: %w", err)
+		return nil, serviceError(err)
 	}
 
+	return newFinalPriceInfoResponse(req.GetId(), result, time.Since(start)), nil
+}
+
+func newFinalPriceInfoResponse(
+	id string,
+	result *domain.SyncResult,
+	duration time.Duration,
+) *pb.GetFinalPriceInfoResponse {
 	return &pb.GetFinalPriceInfoResponse{
-		Id:              req.GetId(),
-		TotalProcessed:  int32(len(processed)),
-		TotalFailed:     int32(len(failed)),
-		ProcessDuration: time.Since(start).String(),
-		Processed:       convertToProtoImportModels(processed),
-		Failed:          convertToProtoItems(failed),
-	}, nil
+		Id:              id,
+		TotalProcessed:  int32(len(result.Processed)),
+		TotalFailed:     int32(len(result.Failed)),
+		ProcessDuration: duration.String(),
+		Processed:       convertToProtoImportModels(result.Processed),
+		Failed:          convertToProtoItems(result.Failed),
+		FailedItems:     convertToProtoFailedItems(result.Failed),
+		CacheHits:       int32(result.CacheHits),
+		CacheMisses:     int32(result.CacheMisses),
+		SnapshotHits:    int32(result.SnapshotHits),
+		TotalRejected:   int32(len(result.Rejected)),
+		Rejected:        convertToProtoRejectedItems(result.Rejected),
+		Coalesced:       int32(result.Coalesced),
+		Placeholders:    convertToProtoPlaceholders(result.Placeholders),
+	}
+}
+
+func runOptions(req *pb.GetFinalPriceInfoRequest) []service.RunOption {
+	return []service.RunOption{
+		service.WithDelta(req.GetDelta()),
+		service.WithMobilePhone(req.GetMobilePhone()),
+	}
+}
+
+// parseProducts rejects the products whose price can't be represented
+// exactly, like NaN or nanos that don't match the units.
+func parseProducts(products []*pb.Item) (parsed []*domain.BasePrice, rejected []*domain.RejectedPrice) {
+	parsed = make([]*domain.BasePrice, 0, len(products))
+	for _, product := range products {
+		price, err := parseMoney(product.GetPriceMoney(), product.GetPrice())
+		if err != nil {
+			rejected = append(rejected, &domain.RejectedPrice{
+				BasePrice: &domain.BasePrice{ProductId: product.GetProductId()},
+				Reason:    domain.RejectInvalidPrice,
+				Error:     err.Error(),
+			})
+			continue
+		}
+		parsed = append(parsed, &domain.BasePrice{
+			ProductId: product.ProductId,
+			Price:     price,
+		})
+	}
+	return parsed, rejected
+}
+
+// parseMoney reads money, or legacy if money is not set.
+func parseMoney(money *pb.Money, legacy float64) (domain.Money, error) {
+	if money == nil {
+		return domain.MoneyFromFloat(legacy, "")
+	}
+	return domain.NewMoney(money.GetUnits(), money.GetNanos(), money.GetCurrencyCode())
+}
+
+func convertToProtoMoney(money domain.Money) *pb.Money {
+	return &pb.Money{
+		CurrencyCode: money.Currency(),
+		Units:        money.Units(),
+		Nanos:        money.Nanos(),
+	}
 }
 
 func convertToProtoImportModels(prices []*domain.ImportModelRep) []*pb.ImportModel {
@@ -82,76 +167,138 @@ func convertToProtoImportModels(prices []*domain.ImportModelRep) []*pb.ImportMod
 	for i, price := range prices {
 		promotions := make([]*pb.Promo, len(price.Promotions))
 		for j, promo := range price.Promotions {
-			parsedPromo := &pb.Promo{
-				Id:         int32(promo.Id),
-				ExternalId: promo.ExternalId,
-				Type:       promo.Type,
-				Name:       promo.Name,
-				SchemaId:   promo.SchemaId,
-			}
-			if promo.StartDate != nil {
-				parsedPromo.StartDate = timestamppb.New(*promo.StartDate)
-			}
-			if promo.EndDate != nil {
-				parsedPromo.EndDate = timestamppb.New(*promo.EndDate)
-			}
-			promotions[j] = parsedPromo
+			promotions[j] = convertToProtoPromo(promo)
 		}
-		promotionPlaceholders := make([]*pb.PromoPlaceholder, len(price.PromoPlaceholders))
-		for j, promoPlaceholder := range price.PromoPlaceholders {
-			parsedPromoPlaceholder := &pb.PromoPlaceholder{
-				PhId:       promoPlaceholder.PhId,
-				PromoId:    int32(promoPlaceholder.PromoId),
-				Type:       promoPlaceholder.Type,
-				Message:    promoPlaceholder.Message,
-				ProductIds: promoPlaceholder.ProductIds,
+		promotionPlaceholders := convertToProtoPlaceholders(price.PromoPlaceholders)
+		accruals := make([]*pb.BalanceAccrual, len(price.Accruals))
+		for j, accrual := range price.Accruals {
+			accruals[j] = &pb.BalanceAccrual{
+				BalanceType: convertToProtoBalanceType(accrual.BalanceType),
+				Amount:      accrual.Amount.Float64(),
+				AmountMoney: convertToProtoMoney(accrual.Amount),
 			}
-
-			if promoPlaceholder.Promo != nil {
-				parsedPromo := &pb.Promo{
-					Id:         int32(promoPlaceholder.Promo.Id),
-					ExternalId: promoPlaceholder.Promo.ExternalId,
-					Type:       promoPlaceholder.Promo.Type,
-					Name:       promoPlaceholder.Promo.Name,
-					SchemaId:   promoPlaceholder.Promo.SchemaId,
-				}
-				if promoPlaceholder.Promo.StartDate != nil {
-					parsedPromo.StartDate = timestamppb.New(*promoPlaceholder.Promo.StartDate)
-				}
-				if promoPlaceholder.Promo.EndDate != nil {
-					parsedPromo.EndDate = timestamppb.New(*promoPlaceholder.Promo.EndDate)
-				}
-				parsedPromoPlaceholder.Promo = parsedPromo
-			}
-			promotionPlaceholders[j] = parsedPromoPlaceholder
 		}
 		result[i] = &pb.ImportModel{
 			FinalPrice:       convertToProtoItem(price.FinalPrice),
 			Promotions:       promotions,
 			PromoPlaceholder: promotionPlaceholders,
+			Source:           string(price.Source),
+			BasePrice:        price.BasePrice.Float64(),
+			Discount:         price.Discount.Float64(),
+			BasePriceMoney:   convertToProtoMoney(price.BasePrice),
+			DiscountMoney:    convertToProtoMoney(price.Discount),
+			Accruals:         accruals,
 		}
 	}
 	return result
 }
 
-func convertToProtoItems(prices []*(domain.BasePrice)) []*pb.Item {
-	result := make([]*pb.Item, len(prices))
+func convertToProtoPlaceholders(placeholders []*domain.PromoPlaceholder) []*pb.PromoPlaceholder {
+	result := make([]*pb.PromoPlaceholder, len(placeholders))
+	for i, promoPlaceholder := range placeholders {
+		parsedPromoPlaceholder := &pb.PromoPlaceholder{
+			PhId:       promoPlaceholder.PhId,
+			PromoId:    int32(promoPlaceholder.PromoId),
+			Type:       promoPlaceholder.Type,
+			Message:    promoPlaceholder.Message,
+			ProductIds: promoPlaceholder.ProductIds,
+		}
+
+		if promoPlaceholder.Promo != nil {
+			parsedPromoPlaceholder.Promo = convertToProtoPromo(promoPlaceholder.Promo)
+		}
+		result[i] = parsedPromoPlaceholder
+	}
+	return result
+}
+
+func convertToProtoPromo(promo *domain.Promo) *pb.Promo {
+	parsedPromo := &pb.Promo{
+		Id:          int32(promo.Id),
+		ExternalId:  promo.ExternalId,
+		Type:        promo.Type,
+		Name:        promo.Name,
+		SchemaId:    promo.SchemaId,
+		Amount:      promo.Amount.Float64(),
+		AmountMoney: convertToProtoMoney(promo.Amount),
+		GroupingKey: promo.GroupingKey,
+		BalanceType: convertToProtoBalanceType(promo.BalanceType),
+	}
+	if promo.StartDate != nil {
+		parsedPromo.StartDate = timestamppb.New(*promo.StartDate)
+	}
+	if promo.EndDate != nil {
+		parsedPromo.EndDate = timestamppb.New(*promo.EndDate)
+	}
+	return parsedPromo
+}
+
+func convertToProtoBalanceType(balanceType *domain.BalanceType) *pb.BalanceType {
+	if balanceType == nil {
+		return nil
+	}
+	return &pb.BalanceType{
+		SystemName: balanceType.SystemName,
+		Name:       balanceType.Name,
+	}
+}
+
+func convertToProtoItems(prices []*domain.FailedPrice) []*pb.Item {
+	result := make([]*pb.Item, 0, len(prices))
 	for _, price := range prices {
 		result = append(result, &pb.Item{
-			ProductId: price.ProductId,
-			Price:     price.Price,
+			ProductId:  price.ProductId,
+			Price:      price.Price.Float64(),
+			PriceMoney: convertToProtoMoney(price.Price),
 		})
 	}
 	return result
 }
 
+func convertToProtoFailedItems(prices []*domain.FailedPrice) []*pb.FailedItem {
+	result := make([]*pb.FailedItem, len(prices))
+	for i, price := range prices {
+		result[i] = &pb.FailedItem{
+			Item: &pb.Item{
+				ProductId:  price.ProductId,
+				Price:      price.Price.Float64(),
+				PriceMoney: convertToProtoMoney(price.Price),
+			},
+			Reason:        string(price.Reason),
+			HttpStatus:    int32(price.HTTPStatus),
+			Body:          price.Body,
+			MindboxStatus: price.MindboxStatus,
+			Retryable:     price.Retryable,
+			Error:         price.Error,
+		}
+	}
+	return result
+}
+
+func convertToProtoRejectedItems(prices []*domain.RejectedPrice) []*pb.RejectedItem {
+	result := make([]*pb.RejectedItem, len(prices))
+	for i, price := range prices {
+		result[i] = &pb.RejectedItem{
+			Item: &pb.Item{
+				ProductId:  price.ProductId,
+				Price:      price.Price.Float64(),
+				PriceMoney: convertToProtoMoney(price.Price),
+			},
+			Reason: string(price.Reason),
+			Error:  price.Error,
+		}
+	}
+	return result
+}
+
 func convertToProtoItem(price *domain.FinalPrice) *pb.Item {
 	if price == nil {
 		return nil
 	}
 	return &pb.Item{
-		ProductId: price.ProductId,
-		Price:     price.Price,
+		ProductId:  price.ProductId,
+		Price:      price.Price.Float64(),
+		PriceMoney: convertToProtoMoney(price.Price),
 	}
 }
 
@@ -162,7 +309,7 @@ func (s *MindboxServer) GetPromotionsInfo(ctx context.Context, req *pb.Empty) (*
 	data, err := s.service.GetPromotionsInfo(ctx)
 	if err != nil {
 		s.logger.Error("Error processing request", "error", err)
-		return nil, fmt.Errorf("failed to get final price info: %w", err)
+		return nil, serviceError(err)
 	}
 
 	return &pb.GetPromoInfoResponse{
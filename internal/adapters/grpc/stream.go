@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	context "context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	grpc "google.golang.org/grpc"
+
+	pb "github.com/ExonegeS/mechta-two-weeks/pkg/grpc"
+)
+
+func (s *MindboxServer) UploadFinalPriceInfo(stream grpc.ClientStreamingServer[pb.GetFinalPriceInfoRequest, pb.GetFinalPriceInfoResponse]) error {
+	var (
+		id          string
+		delta       bool
+		mobilePhone string
+		products    []*domain.BasePrice
+		rejected    []*domain.RejectedPrice
+		first       = true
+	)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			id = chunk.GetId()
+			delta = chunk.GetDelta()
+			mobilePhone = chunk.GetMobilePhone()
+			first = false
+		}
+		parsed, invalid := parseProducts(chunk.GetItems())
+		products = append(products, parsed...)
+		rejected = append(rejected, invalid...)
+	}
+	s.logger.Info("UploadFinalPriceInfo called", "request", id, "products", len(products))
+
+	if len(products) == 0 && len(rejected) == 0 {
+		s.logger.Error("No products provided in request")
+		return fmt.Errorf("no products provided")
+	}
+
+	start := time.Now()
+	result, err := s.service.GetData(stream.Context(), id, time.Now(), products,
+		service.WithDelta(delta), service.WithMobilePhone(mobilePhone), service.WithRejected(rejected...))
+	if err != nil {
+		s.logger.Error("Error processing request", "error", err)
+		return serviceError(err)
+	}
+	return stream.SendAndClose(newFinalPriceInfoResponse(id, result, time.Since(start)))
+}
+
+func (s *MindboxServer) StreamFinalPriceInfo(req *pb.GetFinalPriceInfoRequest, stream grpc.ServerStreamingServer[pb.FinalPriceInfoChunk]) error {
+	s.logger.Info("StreamFinalPriceInfo called", "request", req.GetId(), "products", len(req.GetItems()))
+
+	if len(req.GetItems()) == 0 {
+		s.logger.Error("No products provided in request")
+		return fmt.Errorf("no products provided")
+	}
+
+	start := time.Now()
+	summary := &pb.FinalPriceInfoSummary{}
+	var placeholders domain.PlaceholderGroups
+	if err := s.streamChunk(stream.Context(), stream, req, summary, &placeholders); err != nil {
+		return err
+	}
+	summary.ProcessDuration = time.Since(start).String()
+	summary.Placeholders = convertToProtoPlaceholders(placeholders.List())
+	return stream.Send(&pb.FinalPriceInfoChunk{Id: req.GetId(), Summary: summary})
+}
+
+func (s *MindboxServer) SyncFinalPriceInfo(stream grpc.BidiStreamingServer[pb.GetFinalPriceInfoRequest, pb.FinalPriceInfoChunk]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// Chunks are received in the background, so the client can keep
+	// uploading while earlier chunks are being priced.
+	chunks := make(chan *pb.GetFinalPriceInfoRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(chunks)
+		for {
+			chunk, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					recvErr <- err
+				}
+				return
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var id string
+	start := time.Now()
+	summary := &pb.FinalPriceInfoSummary{}
+	var placeholders domain.PlaceholderGroups
+	for chunk := range chunks {
+		if id == "" {
+			id = chunk.GetId()
+		}
+		if chunk.GetId() == "" {
+			chunk.Id = id
+		}
+		s.logger.Info("SyncFinalPriceInfo chunk received", "request", chunk.GetId(), "products", len(chunk.GetItems()))
+		if err := s.streamChunk(ctx, stream, chunk, summary, &placeholders); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+	}
+	summary.ProcessDuration = time.Since(start).String()
+	summary.Placeholders = convertToProtoPlaceholders(placeholders.List())
+	return stream.Send(&pb.FinalPriceInfoChunk{Id: id, Summary: summary})
+}
+
+// streamChunk prices req and sends one message per finished batch, adding
+// the batch totals to summary and their placeholders to placeholders.
+func (s *MindboxServer) streamChunk(
+	ctx context.Context,
+	stream grpc.ServerStreamingServer[pb.FinalPriceInfoChunk],
+	req *pb.GetFinalPriceInfoRequest,
+	summary *pb.FinalPriceInfoSummary,
+	placeholders *domain.PlaceholderGroups,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	products, rejected := parseProducts(req.GetItems())
+	var sendErr error
+	err := s.service.StreamData(ctx, req.GetId(), time.Now(), products,
+		func(outcome *domain.BatchOutcome) {
+			summary.TotalProcessed += int32(len(outcome.Processed))
+			summary.TotalFailed += int32(len(outcome.Failed))
+			summary.TotalRejected += int32(len(outcome.Rejected))
+			summary.CacheHits += int32(outcome.CacheHits)
+			summary.CacheMisses += int32(outcome.CacheMisses)
+			summary.SnapshotHits += int32(outcome.SnapshotHits)
+			summary.Coalesced += int32(outcome.Coalesced)
+			placeholders.Add(outcome.Processed)
+			if sendErr != nil {
+				return
+			}
+			sendErr = stream.Send(&pb.FinalPriceInfoChunk{
+				Id:          req.GetId(),
+				Processed:   convertToProtoImportModels(outcome.Processed),
+				FailedItems: convertToProtoFailedItems(outcome.Failed),
+				Rejected:    convertToProtoRejectedItems(outcome.Rejected),
+			})
+			if sendErr != nil {
+				// Nobody is listening anymore, so stop sending batches.
+				cancel()
+			}
+		}, append(runOptions(req), service.WithRejected(rejected...))...)
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		s.logger.Error("Error processing request", "error", err)
+		return serviceError(err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
+)
+
+type HealthService interface {
+	Status() domain.HealthStatus
+}
+
+type HealthHandler struct {
+	logger        *slog.Logger
+	healthService HealthService
+}
+
+func NewHealthHandler(logger *slog.Logger, healthService HealthService) *HealthHandler {
+	return &HealthHandler{
+		logger:        logger,
+		healthService: healthService,
+	}
+}
+
+func (h *HealthHandler) RegisterEndpoints(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.Liveness)
+	mux.HandleFunc("GET /readyz", h.Readiness)
+}
+
+// Liveness only says the process is able to serve requests; it doesn't look
+// at Mindbox, so an outage there doesn't get the service restarted.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	type check struct {
+		Name    string `json:"name"`
+		Healthy bool   `json:"healthy"`
+		Error   string `json:"error,omitempty"`
+	}
+	type response struct {
+		Status    string     `json:"status"`
+		Checks    []check    `json:"checks"`
+		CheckedAt *time.Time `json:"checked_at,omitempty"`
+	}
+
+	status := h.healthService.Status()
+	resp := response{Status: "ready"}
+	code := http.StatusOK
+	if !status.Ready {
+		resp.Status = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+	for _, c := range status.Checks {
+		resp.Checks = append(resp.Checks, check{Name: c.Name, Healthy: c.Healthy, Error: c.Error})
+	}
+	if !status.CheckedAt.IsZero() {
+		resp.CheckedAt = &status.CheckedAt
+	}
+	utils.WriteJSON(w, code, resp)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
+)
+
+type JobService interface {
+	Submit(subdivisionId string, calculationTime time.Time, products []*domain.BasePrice, opts ...service.RunOption) (*domain.Job, error)
+	Get(id string) (*domain.Job, error)
+}
+
+type JobHandler struct {
+	logger     *slog.Logger
+	jobService JobService
+}
+
+func NewJobHandler(logger *slog.Logger, jobService JobService) *JobHandler {
+	return &JobHandler{
+		logger:     logger,
+		jobService: jobService,
+	}
+}
+
+func (h *JobHandler) RegisterEndpoints(mux *http.ServeMux) {
+	mux.HandleFunc("POST /jobs/{subdivisionId}", h.SubmitJob)
+	mux.HandleFunc("GET /jobs/{id}", h.GetJob)
+	mux.HandleFunc("GET /jobs/{id}/result", h.GetJobResult)
+}
+
+type jobResponse struct {
+	ID             string     `json:"id"`
+	SubdivisionID  string     `json:"subdivision_id"`
+	Status         string     `json:"status"`
+	Error          string     `json:"error,omitempty"`
+	Progress       float64    `json:"progress"`
+	TotalItems     int        `json:"total_items"`
+	ProcessedItems int        `json:"processed_items"`
+	FailedItems    int        `json:"failed_items"`
+	RejectedItems  int        `json:"rejected_items"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+func newJobResponse(job *domain.Job) jobResponse {
+	return jobResponse{
+		ID:             job.Id,
+		SubdivisionID:  job.SubdivisionId,
+		Status:         string(job.Status),
+		Error:          job.Error,
+		Progress:       job.Progress(),
+		TotalItems:     job.TotalItems,
+		ProcessedItems: job.ProcessedItems,
+		FailedItems:    job.FailedItems,
+		RejectedItems:  job.RejectedItems,
+		CreatedAt:      job.CreatedAt,
+		StartedAt:      job.StartedAt,
+		FinishedAt:     job.FinishedAt,
+	}
+}
+
+func (h *JobHandler) SubmitJob(w http.ResponseWriter, r *http.Request) {
+	const op = "JobHandler.SubmitJob"
+
+	subdivisionId := r.PathValue("subdivisionId")
+
+	req, err := parseDataRequest(r)
+	if err != nil {
+		h.logger.Error("HandlerError", slog.String("operation", op), slog.String("error", err.Error()))
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload"))
+		return
+	}
+
+	job, err := h.jobService.Submit(subdivisionId, time.Now(), req.Items, req.runOptions()...)
+	if err != nil {
+		h.logger.Error("HandlerError",
+			slog.String("operation", op),
+			slog.String("error", err.Error()))
+		if errors.Is(err, service.ErrJobQueueFull) || errors.Is(err, service.ErrDraining) {
+			utils.WriteError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		if errors.Is(err, service.ErrTooManyItems) {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("cannot create job"))
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.Id)
+	utils.WriteJSON(w, http.StatusAccepted, newJobResponse(job))
+}
+
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, newJobResponse(job))
+}
+
+func (h *JobHandler) GetJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	if !job.Done() || job.Result == nil {
+		utils.WriteJSON(w, http.StatusConflict, newJobResponse(job))
+		return
+	}
+
+	var duration time.Duration
+	if job.StartedAt != nil && job.FinishedAt != nil {
+		duration = job.FinishedAt.Sub(*job.StartedAt)
+	}
+	utils.WriteJSON(w, http.StatusOK, newDataResponse(job.SubdivisionId, job.Result, duration))
+}
+
+func (h *JobHandler) lookupJob(w http.ResponseWriter, r *http.Request) (*domain.Job, bool) {
+	id := r.PathValue("id")
+	job, err := h.jobService.Get(id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("job %s not found", id))
+			return nil, false
+		}
+		h.logger.Error("HandlerError", slog.String("operation", "JobHandler.lookupJob"), slog.String("error", err.Error()))
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("cannot access job %s", id))
+		return nil, false
+	}
+	return job, true
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
+)
+
+type ScheduleService interface {
+	List() []*domain.Schedule
+	Get(id string) (*domain.Schedule, error)
+	Pause(id string) (*domain.Schedule, error)
+	Resume(id string) (*domain.Schedule, error)
+	Trigger(ctx context.Context, id string) (*domain.Schedule, error)
+	Promotions() domain.PromotionsRefresh
+}
+
+type ScheduleHandler struct {
+	logger          *slog.Logger
+	scheduleService ScheduleService
+}
+
+func NewScheduleHandler(logger *slog.Logger, scheduleService ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		logger:          logger,
+		scheduleService: scheduleService,
+	}
+}
+
+func (h *ScheduleHandler) RegisterEndpoints(mux *http.ServeMux) {
+	mux.HandleFunc("GET /schedules", h.ListSchedules)
+	mux.HandleFunc("GET /schedules/{id}", h.GetSchedule)
+	mux.HandleFunc("POST /schedules/{id}/pause", h.PauseSchedule)
+	mux.HandleFunc("POST /schedules/{id}/resume", h.ResumeSchedule)
+	mux.HandleFunc("POST /schedules/{id}/trigger", h.TriggerSchedule)
+}
+
+type scheduleResponse struct {
+	ID            string     `json:"id"`
+	SubdivisionID string     `json:"subdivision_id"`
+	Cron          string     `json:"cron"`
+	Source        string     `json:"source"`
+	Overlap       string     `json:"overlap"`
+	Delta         bool       `json:"delta"`
+	Paused        bool       `json:"paused"`
+	Queued        bool       `json:"queued"`
+	NextRun       *time.Time `json:"next_run,omitempty"`
+	LastRun       *time.Time `json:"last_run,omitempty"`
+	LastJobID     string     `json:"last_job_id,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func newScheduleResponse(schedule *domain.Schedule) scheduleResponse {
+	return scheduleResponse{
+		ID:            schedule.Id,
+		SubdivisionID: schedule.SubdivisionId,
+		Cron:          schedule.Cron,
+		Source:        schedule.Source,
+		Overlap:       string(schedule.Overlap),
+		Delta:         schedule.Delta,
+		Paused:        schedule.Paused,
+		Queued:        schedule.Queued,
+		NextRun:       schedule.NextRun,
+		LastRun:       schedule.LastRun,
+		LastJobID:     schedule.LastJobId,
+		LastError:     schedule.LastError,
+	}
+}
+
+func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	type promotions struct {
+		Enabled    bool       `json:"enabled"`
+		Interval   string     `json:"interval,omitempty"`
+		LastRun    *time.Time `json:"last_run,omitempty"`
+		NextRun    *time.Time `json:"next_run,omitempty"`
+		Promotions int        `json:"promotions"`
+		LastError  string     `json:"last_error,omitempty"`
+	}
+	type response struct {
+		Schedules  []scheduleResponse `json:"schedules"`
+		Promotions promotions         `json:"promotions"`
+	}
+
+	schedules := h.scheduleService.List()
+	resp := response{Schedules: make([]scheduleResponse, len(schedules))}
+	for i, schedule := range schedules {
+		resp.Schedules[i] = newScheduleResponse(schedule)
+	}
+	refresh := h.scheduleService.Promotions()
+	resp.Promotions = promotions{
+		Enabled:    refresh.Interval > 0,
+		LastRun:    refresh.LastRun,
+		NextRun:    refresh.NextRun,
+		Promotions: refresh.Promotions,
+		LastError:  refresh.LastError,
+	}
+	if refresh.Interval > 0 {
+		resp.Promotions.Interval = refresh.Interval.String()
+	}
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *ScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.scheduleService.Get(r.PathValue("id"))
+	h.writeSchedule(w, "ScheduleHandler.GetSchedule", http.StatusOK, schedule, err)
+}
+
+func (h *ScheduleHandler) PauseSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.scheduleService.Pause(r.PathValue("id"))
+	h.writeSchedule(w, "ScheduleHandler.PauseSchedule", http.StatusOK, schedule, err)
+}
+
+func (h *ScheduleHandler) ResumeSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.scheduleService.Resume(r.PathValue("id"))
+	h.writeSchedule(w, "ScheduleHandler.ResumeSchedule", http.StatusOK, schedule, err)
+}
+
+// TriggerSchedule starts a run right away. The response carries the job in
+// last_job_id, or queued: true if the run waits for the previous job.
+func (h *ScheduleHandler) TriggerSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.scheduleService.Trigger(r.Context(), r.PathValue("id"))
+	h.writeSchedule(w, "ScheduleHandler.TriggerSchedule", http.StatusAccepted, schedule, err)
+}
+
+func (h *ScheduleHandler) writeSchedule(w http.ResponseWriter, op string, code int, schedule *domain.Schedule, err error) {
+	if err == nil {
+		utils.WriteJSON(w, code, newScheduleResponse(schedule))
+		return
+	}
+
+	h.logger.Error("HandlerError", slog.String("operation", op), slog.String("error", err.Error()))
+	switch {
+	case errors.Is(err, service.ErrScheduleNotFound):
+		utils.WriteError(w, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrScheduleBusy):
+		utils.WriteError(w, http.StatusConflict, err)
+	case errors.Is(err, service.ErrJobQueueFull) || errors.Is(err, service.ErrDraining):
+		utils.WriteError(w, http.StatusServiceUnavailable, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("cannot run schedule"))
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
+)
+
+const (
+	recordProcessed = "processed"
+	recordFailed    = "failed"
+	recordRejected  = "rejected"
+	recordSummary   = "summary"
+	recordError     = "error"
+)
+
+// streamRecord is one line of an NDJSON response. Items are written as soon
+// as their batch completes, and the summary (or an error) is always last.
+type streamRecord struct {
+	Type string `json:"type"`
+	Item any    `json:"item,omitempty"`
+
+	*streamSummary
+	Error string `json:"error,omitempty"`
+}
+
+type streamSummary struct {
+	ID              string `json:"id"`
+	TotalProcessed  int    `json:"total_processed"`
+	TotalFailed     int    `json:"total_failed"`
+	TotalRejected   int    `json:"total_rejected"`
+	CacheHits       int    `json:"cache_hits"`
+	CacheMisses     int    `json:"cache_misses"`
+	SnapshotHits    int    `json:"snapshot_hits"`
+	Coalesced       int    `json:"coalesced"`
+	ProcessDuration string `json:"process_duration"`
+
+	Placeholders []*domain.PromoPlaceholder `json:"placeholders"`
+}
+
+func (h *WorkerHandler) streamData(w http.ResponseWriter, r *http.Request, id string, req *dataRequest, start time.Time) {
+	const op = "WorkerHandler.streamData"
+
+	// The run is made before the header is written, so a rejected request
+	// gets the same status as without streaming.
+	run, err := h.workerService.NewRun(id, time.Now(), req.Items, req.runOptions()...)
+	if err != nil {
+		h.logger.Error("HandlerError",
+			slog.String("operation", op),
+			slog.String("error", err.Error()))
+		writeRunError(w, id, err)
+		return
+	}
+
+	// The request context is not canceled when the client goes away, so
+	// the run is stopped as soon as writing fails.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	w.Header().Set("Content-Type", utils.ContentTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	var writeErr error
+	write := func(rec streamRecord) {
+		if writeErr != nil {
+			return
+		}
+		if writeErr = enc.Encode(rec); writeErr != nil {
+			cancel()
+		}
+	}
+
+	summary := &streamSummary{ID: id}
+	var placeholders domain.PlaceholderGroups
+	err = h.workerService.StreamRun(ctx, run,
+		func(outcome *domain.BatchOutcome) {
+			for _, item := range outcome.Processed {
+				write(streamRecord{Type: recordProcessed, Item: item})
+			}
+			for _, item := range outcome.Failed {
+				write(streamRecord{Type: recordFailed, Item: item})
+			}
+			for _, item := range outcome.Rejected {
+				write(streamRecord{Type: recordRejected, Item: item})
+			}
+			summary.TotalProcessed += len(outcome.Processed)
+			summary.TotalFailed += len(outcome.Failed)
+			summary.TotalRejected += len(outcome.Rejected)
+			summary.CacheHits += outcome.CacheHits
+			summary.CacheMisses += outcome.CacheMisses
+			summary.SnapshotHits += outcome.SnapshotHits
+			summary.Coalesced += outcome.Coalesced
+			placeholders.Add(outcome.Processed)
+			if writeErr == nil {
+				if writeErr = rc.Flush(); writeErr != nil {
+					cancel()
+				}
+			}
+		})
+	if writeErr != nil {
+		h.logger.Error("HandlerError",
+			slog.String("operation", op),
+			slog.String("error", writeErr.Error()))
+		return
+	}
+	if err != nil {
+		h.logger.Error("HandlerError",
+			slog.String("operation", op),
+			slog.String("error", err.Error()))
+		write(streamRecord{Type: recordError, Error: "cannot access data with id " + id})
+		return
+	}
+
+	summary.ProcessDuration = time.Since(start).String()
+	summary.Placeholders = placeholders.List()
+	write(streamRecord{Type: recordSummary, streamSummary: summary})
+}
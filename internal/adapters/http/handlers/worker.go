@@ -2,18 +2,23 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
 	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
 )
 
 type WorkerService interface {
-	GetData(ctx context.Context, subdivisionId string, calculationTime time.Time, products []*domain.BasePrice) ([]*domain.ImportModelRep, []*domain.BasePrice, error)
+	GetData(ctx context.Context, subdivisionId string, calculationTime time.Time, products []*domain.BasePrice, opts ...service.RunOption) (*domain.SyncResult, error)
+	NewRun(subdivisionId string, calculationTime time.Time, products []*domain.BasePrice, opts ...service.RunOption) (*domain.SyncRun, error)
+	StreamRun(ctx context.Context, run *domain.SyncRun, progress service.ProgressFunc) error
 	GetPromotionsInfo(ctx context.Context) ([]*domain.ImportPromotionsRep, error)
+	BatchSizeStats() domain.BatchSizeStats
 }
 
 type WorkerHandler struct {
@@ -34,12 +39,14 @@ func (h *WorkerHandler) RegisterEndpoints(mux *http.ServeMux) {
 	mux.HandleFunc("GET /", h.RootFunc)
 	mux.HandleFunc("GET /data/{id}", h.GetData)
 	mux.HandleFunc("GET /promotions", h.GetPromotionsInfo)
+	mux.HandleFunc("GET /batching", h.GetBatching)
 }
 
 func (h *WorkerHandler) RootFunc(w http.ResponseWriter, r *http.Request) {
 	type request struct {
 		Endpoint string `json:"endpoint"`
 		Body     string `json:"body"`
+		Accept   string `json:"accept,omitempty"`
 	}
 	type response struct {
 		API []request `json:"api"`
@@ -48,11 +55,40 @@ func (h *WorkerHandler) RootFunc(w http.ResponseWriter, r *http.Request) {
 		API: []request{
 			{
 				Endpoint: "/data/{id}",
-				Body:     "{item_list: [{product_id: string, price: numeric}]}",
+				Body:     "{items: [{product_id: string, price: numeric}], delta?: bool, mobile_phone?: string}",
+				Accept:   "application/json, application/x-ndjson",
 			},
 			{
 				Endpoint: "/promotions",
 			},
+			{
+				Endpoint: "/batching",
+			},
+			{
+				Endpoint: "POST /jobs/{subdivisionId}",
+				Body:     "{items: [{product_id: string, price: numeric}], delta?: bool, mobile_phone?: string}",
+			},
+			{
+				Endpoint: "/jobs/{id}",
+			},
+			{
+				Endpoint: "/jobs/{id}/result",
+			},
+			{
+				Endpoint: "/schedules",
+			},
+			{
+				Endpoint: "POST /schedules/{id}/{pause|resume|trigger}",
+			},
+			{
+				Endpoint: "/metrics",
+			},
+			{
+				Endpoint: "/healthz",
+			},
+			{
+				Endpoint: "/readyz",
+			},
 		},
 	})
 }
@@ -71,57 +107,124 @@ func (h *WorkerHandler) GetData(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 
-	type request struct {
-		Items []*struct {
-			ProductId string  `json:"product_id"`
-			Price     float64 `json:"price"`
-		} `json:"items"`
-	}
-	var req request
-	err := utils.ParseJSON(r, &req)
+	req, err := parseDataRequest(r)
 	if err != nil {
 		h.logger.Error("HandlerError", slog.String("operation", op), slog.String("error", err.Error()))
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload"))
 		return
 	}
-	items := make([]*domain.BasePrice, len(req.Items))
-	for i, item := range req.Items {
-		items[i] = &domain.BasePrice{
-			ProductId: item.ProductId,
-			Price:     item.Price,
-		}
+
+	if utils.AcceptsNDJSON(r) {
+		h.streamData(w, r, id, req, start)
+		return
 	}
 
-	data, failed, err := h.workerService.GetData(
+	result, err := h.workerService.GetData(
 		r.Context(),
 		id,
 		time.Now(),
-		items,
+		req.Items,
+		req.runOptions()...,
 	)
 	if err != nil {
 		h.logger.Error("HandlerError",
 			slog.String("operation", op),
 			slog.String("error", err.Error()))
+		writeRunError(w, id, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, newDataResponse(id, result, time.Since(start)))
+}
+
+// writeRunError answers a run that failed or was refused by the service.
+func writeRunError(w http.ResponseWriter, id string, err error) {
+	switch {
+	case errors.Is(err, service.ErrDraining):
+		utils.WriteError(w, http.StatusServiceUnavailable, err)
+	case errors.Is(err, service.ErrTooManyItems):
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
 		utils.WriteError(w, http.StatusInternalServerError,
 			fmt.Errorf("cannot access data with id %s", id))
-		return
 	}
-	utils.WriteJSON(w, http.StatusOK, struct {
-		ID              string `json:"id"`
-		TotalProcessed  int    `json:"total_processed"`
-		TotalFailed     int    `json:"total_failed"`
-		ProcessDuration string `json:"process_duration"`
+}
 
-		Processed any                 `json:"processed"`
-		Failed    []*domain.BasePrice `json:"failed"`
-	}{
+type dataResponse struct {
+	ID              string `json:"id"`
+	TotalProcessed  int    `json:"total_processed"`
+	TotalFailed     int    `json:"total_failed"`
+	TotalRejected   int    `json:"total_rejected"`
+	CacheHits       int    `json:"cache_hits"`
+	CacheMisses     int    `json:"cache_misses"`
+	SnapshotHits    int    `json:"snapshot_hits"`
+	Coalesced       int    `json:"coalesced"`
+	ProcessDuration string `json:"process_duration"`
+	// Currency of every price in the response.
+	Currency string `json:"currency"`
+
+	Processed any                     `json:"processed"`
+	Failed    []*domain.FailedPrice   `json:"failed"`
+	Rejected  []*domain.RejectedPrice `json:"rejected"`
+	// Placeholders lists every placeholder once with all products
+	// showing it.
+	Placeholders []*domain.PromoPlaceholder `json:"placeholders"`
+}
+
+func newDataResponse(id string, result *domain.SyncResult, duration time.Duration) dataResponse {
+	return dataResponse{
 		ID:              id,
-		TotalProcessed:  len(data),
-		TotalFailed:     len(failed),
-		Processed:       data,
-		Failed:          failed,
-		ProcessDuration: time.Since(start).String(),
-	})
+		TotalProcessed:  len(result.Processed),
+		TotalFailed:     len(result.Failed),
+		TotalRejected:   len(result.Rejected),
+		CacheHits:       result.CacheHits,
+		CacheMisses:     result.CacheMisses,
+		SnapshotHits:    result.SnapshotHits,
+		Coalesced:       result.Coalesced,
+		Processed:       result.Processed,
+		Failed:          result.Failed,
+		Rejected:        result.Rejected,
+		Placeholders:    result.Placeholders,
+		ProcessDuration: duration.String(),
+		Currency:        domain.DefaultCurrency,
+	}
+}
+
+// dataRequest is the body shared by /data/{id} and POST /jobs/{subdivisionId}.
+type dataRequest struct {
+	Items       []*domain.BasePrice
+	Delta       bool
+	MobilePhone string
+}
+
+func (req *dataRequest) runOptions() []service.RunOption {
+	return []service.RunOption{
+		service.WithDelta(req.Delta),
+		service.WithMobilePhone(req.MobilePhone),
+	}
+}
+
+func parseDataRequest(r *http.Request) (*dataRequest, error) {
+	type request struct {
+		Items []*struct {
+			ProductId string       `json:"product_id"`
+			Price     domain.Money `json:"price"`
+		} `json:"items"`
+		Delta bool `json:"delta"`
+		// MobilePhone asks for the personal prices of a customer.
+		MobilePhone string `json:"mobile_phone"`
+	}
+	var req request
+	if err := utils.ParseJSON(r, &req); err != nil {
+		return nil, err
+	}
+	items := make([]*domain.BasePrice, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = &domain.BasePrice{
+			ProductId: item.ProductId,
+			Price:     item.Price,
+		}
+	}
+	return &dataRequest{Items: items, Delta: req.Delta, MobilePhone: req.MobilePhone}, nil
 }
 
 func (h *WorkerHandler) GetPromotionsInfo(w http.ResponseWriter, r *http.Request) {
@@ -168,8 +271,12 @@ func (h *WorkerHandler) GetPromotionsInfo(w http.ResponseWriter, r *http.Request
 			ExternalID: promo.ExternalID,
 			Name:       promo.Name,
 			SchemaID:   promo.SchemaID,
-			StartDate:  promo.StartDate.Format(time.RFC3339),
-			EndDate:    promo.EndDate.Format(time.RFC3339),
+		}
+		if promo.StartDate != nil {
+			resp[i].StartDate = promo.StartDate.Format(time.RFC3339)
+		}
+		if promo.EndDate != nil {
+			resp[i].EndDate = promo.EndDate.Format(time.RFC3339)
 		}
 	}
 
@@ -179,3 +286,28 @@ func (h *WorkerHandler) GetPromotionsInfo(w http.ResponseWriter, r *http.Request
 		Promotions:      resp,
 	})
 }
+
+func (h *WorkerHandler) GetBatching(w http.ResponseWriter, r *http.Request) {
+	stats := h.workerService.BatchSizeStats()
+
+	type response struct {
+		Current       int        `json:"current"`
+		Min           int        `json:"min"`
+		Max           int        `json:"max"`
+		LatencyTarget string     `json:"latency_target"`
+		LastReason    string     `json:"last_reason,omitempty"`
+		LastChange    *time.Time `json:"last_change,omitempty"`
+	}
+
+	resp := response{
+		Current:       stats.Current,
+		Min:           stats.Min,
+		Max:           stats.Max,
+		LatencyTarget: stats.LatencyTarget.String(),
+		LastReason:    stats.LastReason,
+	}
+	if !stats.LastChange.IsZero() {
+		resp.LastChange = &stats.LastChange
+	}
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	"github.com/ExonegeS/mechta-two-weeks/internal/utils"
+)
+
+// NewAuthMW lets a request through only if its credential grants the scope
+// of the longest path prefix in scopes that matches. Paths matching no
+// prefix are public. The credential is read from an
+// "Authorization: Bearer" header or an X-API-Key header. With a nil
+// Authenticator every request is let through.
+func NewAuthMW(logger *slog.Logger, auth service.Authenticator, scopes map[string]domain.Scope) Middleware {
+	return func(next http.Handler) http.Handler {
+		if auth == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, ok := scopeFor(scopes, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, err := service.Authorize(auth, credential(r), scope)
+			if err != nil {
+				logger.Warn("request rejected",
+					slog.String("path", r.URL.Path),
+					slog.String("error", err.Error()))
+				if errors.Is(err, service.ErrForbidden) {
+					utils.WriteError(w, http.StatusForbidden, err)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				utils.WriteError(w, http.StatusUnauthorized, service.ErrUnauthenticated)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(service.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func scopeFor(scopes map[string]domain.Scope, path string) (domain.Scope, bool) {
+	var (
+		scope   domain.Scope
+		longest = -1
+	)
+	for prefix, s := range scopes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			scope, longest = s, len(prefix)
+		}
+	}
+	return scope, longest >= 0
+}
+
+func credential(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get("X-API-Key")
+}
@@ -24,50 +24,80 @@ type ConfigSt struct {
 
 	RetryCount         int
 	RetryInterval      time.Duration
+	RetryMaxInterval   time.Duration
+	RetryBudget        int
+	RetryBudgetRatio   float64
 	InsecureSkipVerify bool
 
-	MaxRetries    int
-	ResetDuration time.Duration
+	MaxRetries     int
+	ResetDuration  time.Duration
+	HalfOpenProbes int
+	// IsFailure and OnStateChange are passed to the circuit breaker as is.
+	IsFailure     func(err error) bool
+	OnStateChange func(from, to httpclient.State)
 	SECRET_KEY    string
+
+	RequestsPerSecond float64
+	ItemsPerSecond    float64
+
+	Metrics *httpclient.Metrics
 }
 type Client struct {
-	apiClient *httpclient.APIClient
-	config    *ConfigSt
+	apiClient    *httpclient.APIClient
+	config       *ConfigSt
+	itemsLimiter *httpclient.RateLimiter
 }
 
 func New(cfg *ConfigSt) (*Client, error) {
 	opts := &httpclient.OptionsSt{
 		Timeout: cfg.Timeout,
 
-		RetryCount:    cfg.RetryCount,
-		RetryInterval: cfg.RetryInterval,
+		RetryCount:       cfg.RetryCount,
+		RetryInterval:    cfg.RetryInterval,
+		RetryMaxInterval: cfg.RetryMaxInterval,
+		RetryBudget:      cfg.RetryBudget,
+		RetryBudgetRatio: cfg.RetryBudgetRatio,
 
 		InsecureSkipVerify: cfg.InsecureSkipVerify,
+
+		RequestsPerSecond: cfg.RequestsPerSecond,
+		RequestsBurst:     int(cfg.RequestsPerSecond),
+
+		Metrics: cfg.Metrics,
 	}
 	opts.Normalize()
-	cb := httpclient.NewCircuitBreaker(
-		cfg.MaxRetries,
-		cfg.ResetDuration,
-	)
+	cb := httpclient.NewCircuitBreakerWithSettings(httpclient.CircuitBreakerSettings{
+		MaxFailures:    cfg.MaxRetries,
+		ResetTimeout:   cfg.ResetDuration,
+		HalfOpenProbes: cfg.HalfOpenProbes,
+		IsFailure:      cfg.IsFailure,
+		OnStateChange:  cfg.OnStateChange,
+	})
 	apiClient, err := httpclient.NewAPIClient(cfg.Uri, opts, cb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create API client: %w", err)
 	}
 	return &Client{
-		apiClient: apiClient,
-		config:    cfg,
+		apiClient:    apiClient,
+		config:       cfg,
+		itemsLimiter: httpclient.NewRateLimiter(cfg.ItemsPerSecond, int(cfg.ItemsPerSecond)),
 	}, nil
 }
 
 func (c *Client) GetFinalPriceInfo(ctx context.Context, reqObj *domain.ImportModelReq) ([]*domain.ImportModelRep, error) {
+	// Every attempt, retries included, takes a token per product.
+	ctx = httpclient.WithRateLimit(ctx, c.itemsLimiter, len(reqObj.Products))
+
 	data := domain.SubdivisionGetInfoReq{}
 	data.Encode(reqObj)
 	req, err := c.apiClient.NewRequest(http.MethodPost, PathOperationsSync).
 		WithQueryParam("operation", "Shop.GetProductInfo").
+		WithOperation("Shop.GetProductInfo").
 		WithQueryParam("endpointId", "MECHTA").
 		WithJSONBody(data).
 		WithContext(ctx).
-		WithHeader("Authorization", fmt.Sprintf("Mindbox secretKey=\"%s\"", c.config.SECRET_KEY)).
+		WithHeader("Authorization", c.authorization()).
+		Idempotent().
 		Build()
 	if err != nil {
 		return nil, err
@@ -79,16 +109,20 @@ func (c *Client) GetFinalPriceInfo(ctx context.Context, reqObj *domain.ImportMod
 	}
 
 	if repObj.Status != StatusSuccess {
-		return nil, errors.New("bad status")
+		return nil, &domain.StatusError{Status: repObj.Status}
 	}
 
 	if repObj.ProductList.ProcessingStatus != ProcessingStatusCalculated {
-		return nil, errors.New("invalid processing status")
+		return nil, &domain.StatusError{Status: repObj.ProductList.ProcessingStatus, Processing: true}
 	}
 
 	return processProductItems(repObj.ProductList.Items), nil
 }
 
+func (c *Client) authorization() string {
+	return fmt.Sprintf("Mindbox secretKey=\"%s\"", c.config.SECRET_KEY)
+}
+
 func processProductItems(items []*domain.SubdivisionGetInfoRepItem) []*domain.ImportModelRep {
 	result := make([]*domain.ImportModelRep, 0, len(items))
 	for _, item := range items {
@@ -139,8 +173,10 @@ func (c *Client) GetExportData(ctx context.Context, operation string) (*domain.P
 func (c *Client) startExport(ctx context.Context, operation string) (string, error) {
 	req, err := c.apiClient.NewRequest(http.MethodPost, PathOperationsSync).
 		WithQueryParam("operation", operation).
+		WithOperation("ExportStart").
 		WithContext(ctx).
 		WithJSONBody(nil).
+		WithHeader("Authorization", c.authorization()).
 		Build()
 	if err != nil {
 		return "", err
@@ -179,7 +215,12 @@ func (c *Client) waitForExport(ctx context.Context, operation, exportID string)
 func (c *Client) checkExportStatus(ctx context.Context, operation, exportID string) (string, bool, error) {
 	req, err := c.apiClient.NewRequest(http.MethodPost, PathOperationsSync).
 		WithQueryParam("operation", operation).
-		WithJSONBody(map[string]string{"exportId": exportID}).Build()
+		WithOperation("ExportStatus").
+		WithContext(ctx).
+		WithJSONBody(map[string]string{"exportId": exportID}).
+		WithHeader("Authorization", c.authorization()).
+		Idempotent().
+		Build()
 	if err != nil {
 		return "", false, err
 	}
@@ -200,6 +241,7 @@ func (c *Client) checkExportStatus(ctx context.Context, operation, exportID stri
 
 func (c *Client) fetchExportData(ctx context.Context, fileURL string) (*domain.PromotionsGetInfoRepSt, error) {
 	req, err := c.apiClient.NewRequest(http.MethodGet, fileURL).
+		WithOperation("ExportDownload").
 		WithContext(ctx).
 		Build()
 	if err != nil {
@@ -211,3 +253,12 @@ func (c *Client) fetchExportData(ctx context.Context, fileURL string) (*domain.P
 	}
 	return &result, nil
 }
+
+// Ping checks that Mindbox is reachable without spending rate limit.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.apiClient.Ping(ctx)
+}
+
+func (c *Client) CircuitState() httpclient.State {
+	return c.apiClient.CircuitState()
+}
@@ -0,0 +1,371 @@
+// Package mindboxmock is a stand-in for the parts of the Mindbox API the
+// service uses: Shop.GetProductInfo and the export flow (start, poll until
+// Ready, download). It can be embedded in tests with Start or served by
+// cmd/mindbox-mock.
+package mindboxmock
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+const (
+	OperationGetProductInfo = "Shop.GetProductInfo"
+
+	pathSync    = "/operations/sync"
+	pathExports = "/exports/"
+)
+
+type Promotion struct {
+	MindboxId  int64  `json:"mindboxId"`
+	ExternalId string `json:"externalId"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+
+	GroupingKey string `json:"groupingKey"`
+	// BalanceType makes the promotion accrue Accrual (0.05 is 5% of the base
+	// price) to that balance instead of giving a discount.
+	BalanceType string  `json:"balanceType"`
+	Accrual     float64 `json:"accrual"`
+}
+
+type Placeholder struct {
+	ExternalId string    `json:"externalId"`
+	Type       string    `json:"type"`
+	Message    string    `json:"message"`
+	Promotion  Promotion `json:"promotion"`
+}
+
+type Config struct {
+	// SecretKey, when set, is required in the Authorization header.
+	SecretKey string `json:"secretKey"`
+
+	// Prices maps product IDs to the price for customer. Other products get
+	// their base price reduced by Discount (0.1 is 10% off).
+	Prices       map[string]domain.Money `json:"prices"`
+	Discount     float64                 `json:"discount"`
+	Promotions   []Promotion             `json:"promotions"`
+	Placeholders []Placeholder           `json:"placeholders"`
+
+	// RejectProducts makes every batch that contains one of these products
+	// fail with RejectStatus (400 by default).
+	RejectProducts []string `json:"rejectProducts"`
+	RejectStatus   int      `json:"rejectStatus"`
+
+	Latency       time.Duration `json:"-"`
+	LatencyJitter time.Duration `json:"-"`
+	// ErrorRate is the share of requests answered with ErrorStatus (500 by
+	// default) instead of being processed.
+	ErrorRate   float64 `json:"errorRate"`
+	ErrorStatus int     `json:"errorStatus"`
+	// Status and ProcessingStatus override the values reported in a
+	// successful GetProductInfo response.
+	Status           string `json:"status"`
+	ProcessingStatus string `json:"processingStatus"`
+
+	// ExportPolls is how many status checks report NotReady before an
+	// export becomes Ready.
+	ExportPolls      int                  `json:"exportPolls"`
+	ExportPromotions []domain.PromotionSt `json:"exportPromotions"`
+}
+
+type Stats struct {
+	Requests      int64
+	ProductsSeen  int64
+	Errors        int64
+	ExportsServed int64
+}
+
+type Server struct {
+	mu      sync.RWMutex
+	cfg     Config
+	exports map[string]int
+
+	nextExport    atomic.Int64
+	requests      atomic.Int64
+	productsSeen  atomic.Int64
+	errors        atomic.Int64
+	exportsServed atomic.Int64
+}
+
+func New(cfg Config) *Server {
+	return &Server{
+		cfg:     cfg,
+		exports: make(map[string]int),
+	}
+}
+
+// Start serves a new mock on a local port. Callers should Close the
+// returned server; its URL is the Mindbox URI to configure.
+func Start(cfg Config) (*Server, *httptest.Server) {
+	s := New(cfg)
+	return s, httptest.NewServer(s)
+}
+
+// Update changes the configuration of a running mock, e.g. to inject
+// failures in the middle of a test.
+func (s *Server) Update(f func(cfg *Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(&s.cfg)
+}
+
+func (s *Server) Stats() Stats {
+	return Stats{
+		Requests:      s.requests.Load(),
+		ProductsSeen:  s.productsSeen.Load(),
+		Errors:        s.errors.Load(),
+		ExportsServed: s.exportsServed.Load(),
+	}
+}
+
+func (s *Server) config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	cfg := s.config()
+
+	// Export files are served like presigned links and need no key.
+	if cfg.SecretKey != "" && r.URL.Path == pathSync && r.Header.Get("Authorization") != fmt.Sprintf("Mindbox secretKey=\"%s\"", cfg.SecretKey) {
+		s.writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	delay := cfg.Latency
+	if cfg.LatencyJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(cfg.LatencyJitter)))
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if cfg.ErrorRate > 0 && rand.Float64() < cfg.ErrorRate {
+		s.writeError(w, cmp.Or(cfg.ErrorStatus, http.StatusInternalServerError), "InternalServerError")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == pathSync:
+		if r.URL.Query().Get("operation") == OperationGetProductInfo {
+			s.getProductInfo(w, r, cfg)
+			return
+		}
+		s.export(w, r, cfg)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, pathExports):
+		s.exportsServed.Add(1)
+		writeJSON(w, http.StatusOK, domain.PromotionsGetInfoRepSt{Promotions: cfg.ExportPromotions})
+	default:
+		s.writeError(w, http.StatusNotFound, "NotFound")
+	}
+}
+
+type productInfoItem struct {
+	Product struct {
+		Ids struct {
+			Mechtakz string `json:"mechtakz"`
+		} `json:"ids"`
+	} `json:"product"`
+	BasePricePerItem  domain.Money       `json:"basePricePerItem"`
+	PriceForCustomer  domain.Money       `json:"priceForCustomer"`
+	AppliedPromotions []appliedPromotion `json:"appliedPromotions"`
+	Placeholders      []placeholder      `json:"placeholders"`
+}
+
+type appliedPromotion struct {
+	Type      string `json:"type"`
+	Promotion struct {
+		Ids struct {
+			MindboxId  int64  `json:"mindboxId"`
+			ExternalId string `json:"externalId"`
+		} `json:"ids"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"promotion"`
+	GroupingKey string       `json:"groupingKey,omitempty"`
+	BalanceType *balanceType `json:"balanceType,omitempty"`
+	Amount      domain.Money `json:"amount"`
+}
+
+type balanceType struct {
+	Ids struct {
+		SystemName string `json:"systemName"`
+	} `json:"ids"`
+	Name string `json:"name"`
+}
+
+type placeholder struct {
+	Ids struct {
+		ExternalId string `json:"externalId"`
+	} `json:"ids"`
+	Content []placeholderContent `json:"content"`
+}
+
+type placeholderContent struct {
+	Type      string `json:"type"`
+	Promotion struct {
+		Ids struct {
+			MindboxId  int64  `json:"mindboxId"`
+			ExternalId string `json:"externalId"`
+		} `json:"ids"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"promotion"`
+	Message string `json:"message"`
+}
+
+func (s *Server) getProductInfo(w http.ResponseWriter, r *http.Request, cfg Config) {
+	var req domain.SubdivisionGetInfoReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "ProtocolError")
+		return
+	}
+	s.productsSeen.Add(int64(len(req.ProductList.Items)))
+
+	items := make([]productInfoItem, 0, len(req.ProductList.Items))
+	for _, in := range req.ProductList.Items {
+		productId := in.Product.Ids.Mechtakz
+		for _, rejected := range cfg.RejectProducts {
+			if productId == rejected {
+				s.writeError(w, cmp.Or(cfg.RejectStatus, http.StatusBadRequest), "ValidationError")
+				return
+			}
+		}
+
+		item := productInfoItem{
+			BasePricePerItem: in.BasePricePerItem,
+			PriceForCustomer: share(in.BasePricePerItem, 1-cfg.Discount),
+		}
+		item.Product.Ids.Mechtakz = productId
+		if price, ok := cfg.Prices[productId]; ok {
+			item.PriceForCustomer = price
+		}
+
+		discount := item.BasePricePerItem.Sub(item.PriceForCustomer)
+		for _, p := range cfg.Promotions {
+			applied := appliedPromotion{Type: "discount", GroupingKey: p.GroupingKey}
+			applied.Promotion.Ids.MindboxId = p.MindboxId
+			applied.Promotion.Ids.ExternalId = p.ExternalId
+			applied.Promotion.Name = p.Name
+			applied.Promotion.Type = p.Type
+			switch {
+			case p.BalanceType != "":
+				applied.Type = "earnedBonusPoints"
+				applied.BalanceType = &balanceType{Name: p.BalanceType}
+				applied.BalanceType.Ids.SystemName = p.BalanceType
+				applied.Amount = share(in.BasePricePerItem, p.Accrual)
+			default:
+				// The whole discount is attributed to the first
+				// discount promotion.
+				applied.Amount = discount
+				discount = domain.Money{}
+			}
+			item.AppliedPromotions = append(item.AppliedPromotions, applied)
+		}
+		for _, p := range cfg.Placeholders {
+			content := placeholderContent{Type: p.Type, Message: p.Message}
+			content.Promotion.Ids.MindboxId = p.Promotion.MindboxId
+			content.Promotion.Ids.ExternalId = p.Promotion.ExternalId
+			content.Promotion.Name = p.Promotion.Name
+			content.Promotion.Type = p.Promotion.Type
+			ph := placeholder{Content: []placeholderContent{content}}
+			ph.Ids.ExternalId = p.ExternalId
+			item.Placeholders = append(item.Placeholders, ph)
+		}
+		items = append(items, item)
+	}
+
+	type productList struct {
+		ProcessingStatus string            `json:"processingStatus"`
+		Items            []productInfoItem `json:"items"`
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Status      string      `json:"status"`
+		ProductList productList `json:"productList"`
+	}{
+		Status: cmp.Or(cfg.Status, "Success"),
+		ProductList: productList{
+			ProcessingStatus: cmp.Or(cfg.ProcessingStatus, "Calculated"),
+			Items:            items,
+		},
+	})
+}
+
+func (s *Server) export(w http.ResponseWriter, r *http.Request, cfg Config) {
+	var body struct {
+		ExportId string `json:"exportId"`
+	}
+	// Starting an export sends a null body, so a decode error is not fatal.
+	json.NewDecoder(r.Body).Decode(&body)
+
+	var rep domain.ExportRepSt
+	rep.Status = "Success"
+
+	if body.ExportId == "" {
+		rep.ExportID = strconv.FormatInt(s.nextExport.Add(1), 10)
+		s.mu.Lock()
+		s.exports[rep.ExportID] = 0
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, rep)
+		return
+	}
+
+	s.mu.Lock()
+	polls, ok := s.exports[body.ExportId]
+	if ok {
+		s.exports[body.ExportId] = polls + 1
+	}
+	s.mu.Unlock()
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "NotFound")
+		return
+	}
+
+	rep.ExportID = body.ExportId
+	if polls < cfg.ExportPolls {
+		rep.ExportResult.ProcessingStatus = "NotReady"
+		writeJSON(w, http.StatusOK, rep)
+		return
+	}
+	rep.ExportResult.ProcessingStatus = "Ready"
+	rep.ExportResult.Urls = []string{fmt.Sprintf("http://%s%s%s.json", r.Host, pathExports, body.ExportId)}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, code int, status string) {
+	s.errors.Add(1)
+	writeJSON(w, code, map[string]string{"status": status})
+}
+
+// share returns ratio of amount, rounded to minor units like Mindbox does.
+func share(amount domain.Money, ratio float64) domain.Money {
+	m, err := domain.MoneyFromFloat(amount.Float64()*ratio, amount.Currency())
+	if err != nil {
+		return domain.Money{}
+	}
+	return m.RoundMinor(domain.RoundHalfUp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
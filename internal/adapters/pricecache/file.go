@@ -0,0 +1,170 @@
+package pricecache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// File serves reads from memory and appends every write to a JSON lines
+// file, so the cache survives restarts. The file is rewritten with only the
+// live entries when it is opened and whenever expired entries are dropped.
+// Personal prices are kept in memory only, so customer phone numbers, which
+// are part of their keys, never reach the disk.
+type File struct {
+	*Memory
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	f := &File{
+		Memory: NewMemory(),
+		path:   path,
+	}
+	if err := f.load(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := f.DeleteExpired(time.Now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Put(entries []*domain.PriceCacheEntry) error {
+	f.Memory.Put(entries)
+	entries = persistent(entries)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := bufio.NewWriter(f.file)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (f *File) DeleteExpired(now time.Time) error {
+	f.Memory.DeleteExpired(now)
+	return f.rewrite()
+}
+
+func (f *File) Clear() error {
+	f.Memory.Clear()
+	return f.rewrite()
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// load reads the entries written by a previous process. A torn last line is
+// ignored; rewrite drops it from the file.
+func (f *File) load() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var entries []*domain.PriceCacheEntry
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		var entry domain.PriceCacheEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			break
+		}
+		entries = append(entries, &entry)
+	}
+	return f.Memory.Put(entries)
+}
+
+// rewrite replaces the file with the entries currently in memory.
+func (f *File) rewrite() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp := f.path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, entry := range persistent(f.Memory.all()) {
+		if err := enc.Encode(entry); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0644)
+	return err
+}
+
+// persistent returns the entries that may be written to disk, leaving out
+// personal prices.
+func persistent(entries []*domain.PriceCacheEntry) []*domain.PriceCacheEntry {
+	result := make([]*domain.PriceCacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Personal {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
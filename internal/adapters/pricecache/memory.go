@@ -0,0 +1,69 @@
+package pricecache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// Memory keeps the cache in a map. It is lost on restart.
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]*domain.PriceCacheEntry
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		entries: make(map[string]*domain.PriceCacheEntry),
+	}
+}
+
+func (m *Memory) Get(key string) (*domain.PriceCacheEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[key]
+	return entry, ok
+}
+
+func (m *Memory) Put(entries []*domain.PriceCacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range entries {
+		m.entries[entry.Key] = entry
+	}
+	return nil
+}
+
+func (m *Memory) DeleteExpired(now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, entry := range m.entries {
+		if !now.Before(entry.ExpiresAt) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make(map[string]*domain.PriceCacheEntry)
+	return nil
+}
+
+func (m *Memory) all() []*domain.PriceCacheEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]*domain.PriceCacheEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,57 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// LoadFile reads schedules from a JSON file like
+//
+//	{"schedules": [{
+//		"id": "almaty-nightly",
+//		"subdivision_id": "almaty",
+//		"cron": "0 2 * * *",
+//		"source": "https://catalog.local/almaty.json",
+//		"overlap": "skip",
+//		"delta": true
+//	}]}
+func LoadFile(path string) ([]*domain.Schedule, error) {
+	type schedule struct {
+		Id            string `json:"id"`
+		SubdivisionId string `json:"subdivision_id"`
+		Cron          string `json:"cron"`
+		Source        string `json:"source"`
+		Overlap       string `json:"overlap"`
+		Delta         bool   `json:"delta"`
+		Paused        bool   `json:"paused"`
+	}
+	type file struct {
+		Schedules []*schedule `json:"schedules"`
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	schedules := make([]*domain.Schedule, len(f.Schedules))
+	for i, s := range f.Schedules {
+		schedules[i] = &domain.Schedule{
+			Id:            s.Id,
+			SubdivisionId: s.SubdivisionId,
+			Cron:          s.Cron,
+			Source:        s.Source,
+			Overlap:       domain.OverlapPolicy(s.Overlap),
+			Delta:         s.Delta,
+			Paused:        s.Paused,
+		}
+	}
+	return schedules, nil
+}
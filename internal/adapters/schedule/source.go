@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// Source reads products from a local file or an http(s) URL. Both hold the
+// same body /data/{id} accepts: {"items": [{"product_id": ..., "price": ...}]}.
+type Source struct {
+	client *http.Client
+}
+
+func NewSource(timeout time.Duration) *Source {
+	return &Source{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *Source) Load(ctx context.Context, location string) ([]*domain.BasePrice, error) {
+	var (
+		body io.ReadCloser
+		err  error
+	)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		body, err = s.fetch(ctx, location)
+	} else {
+		body, err = os.Open(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source %s: %w", location, err)
+	}
+	defer body.Close()
+
+	type request struct {
+		Items []*struct {
+			ProductId string       `json:"product_id"`
+			Price     domain.Money `json:"price"`
+		} `json:"items"`
+	}
+	var req request
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to parse source %s: %w", location, err)
+	}
+	products := make([]*domain.BasePrice, len(req.Items))
+	for i, item := range req.Items {
+		products[i] = &domain.BasePrice{
+			ProductId: item.ProductId,
+			Price:     item.Price,
+		}
+	}
+	return products, nil
+}
+
+func (s *Source) fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,173 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// File serves reads from memory and appends every write to a JSON lines
+// file, so snapshots survive restarts. A product that is synced every night
+// appends a line every night, so the file is rewritten with only the latest
+// snapshot per product when it is opened and whenever it holds twice as many
+// lines as there are products. Snapshots of personal prices are kept in
+// memory only, so no customer phone number is written to disk.
+type File struct {
+	*Memory
+	path string
+
+	mu    sync.Mutex
+	file  *os.File
+	lines int
+}
+
+func NewFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	f := &File{
+		Memory: NewMemory(),
+		path:   path,
+	}
+	if err := f.load(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.rewrite(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Put(snapshots []*domain.PriceSnapshot) error {
+	f.Memory.Put(snapshots)
+	snapshots = persistent(snapshots)
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := bufio.NewWriter(f.file)
+	enc := json.NewEncoder(w)
+	for _, snapshot := range snapshots {
+		if err := enc.Encode(snapshot); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	f.lines += len(snapshots)
+	if f.lines > 2*f.Memory.len() {
+		return f.rewrite()
+	}
+	return nil
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// load reads the snapshots written by a previous process. A torn last line
+// is ignored; rewrite drops it from the file.
+func (f *File) load() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var snapshots []*domain.PriceSnapshot
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		var snapshot domain.PriceSnapshot
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			break
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	// Files written by older versions may hold personal snapshots; rewrite
+	// drops them.
+	return f.Memory.Put(persistent(snapshots))
+}
+
+// rewrite replaces the file with the snapshots currently in memory. f.mu
+// must be held.
+func (f *File) rewrite() error {
+	tmp := f.path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	snapshots := persistent(f.Memory.all())
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, snapshot := range snapshots {
+		if err := enc.Encode(snapshot); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.lines = len(snapshots)
+	f.file, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0644)
+	return err
+}
+
+// persistent returns the snapshots that may be written to disk, leaving out
+// the ones of personal prices.
+func persistent(snapshots []*domain.PriceSnapshot) []*domain.PriceSnapshot {
+	result := make([]*domain.PriceSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.MobilePhone == "" {
+			result = append(result, snapshot)
+		}
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"sync"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// Memory keeps the snapshots in a map. They are lost on restart.
+type Memory struct {
+	mu        sync.RWMutex
+	snapshots map[string]*domain.PriceSnapshot
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		snapshots: make(map[string]*domain.PriceSnapshot),
+	}
+}
+
+func (m *Memory) Get(subdivisionId, mobilePhone, productId string) (*domain.PriceSnapshot, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot, ok := m.snapshots[key(subdivisionId, mobilePhone, productId)]
+	return snapshot, ok
+}
+
+func (m *Memory) Put(snapshots []*domain.PriceSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		m.snapshots[key(snapshot.SubdivisionId, snapshot.MobilePhone, snapshot.ProductId)] = snapshot
+	}
+	return nil
+}
+
+func (m *Memory) len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.snapshots)
+}
+
+func (m *Memory) all() []*domain.PriceSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshots := make([]*domain.PriceSnapshot, 0, len(m.snapshots))
+	for _, snapshot := range m.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+func key(subdivisionId, mobilePhone, productId string) string {
+	return subdivisionId + "|" + mobilePhone + "|" + productId
+}
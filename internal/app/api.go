@@ -1,17 +1,30 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/filequeue"
 	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/grpc"
 	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/http/handlers"
 	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/http/middleware"
 	mind_box "github.com/ExonegeS/mechta-two-weeks/internal/adapters/mindbox"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/pricecache"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/schedule"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/snapshot"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
 	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	grpclib "google.golang.org/grpc"
 )
 
 type APIServer struct {
@@ -26,39 +39,241 @@ func NewAPIServer(config *config.Config, logger *slog.Logger) *APIServer {
 	}
 }
 
-func (s *APIServer) Run() error {
+// Run serves HTTP and gRPC until ctx is done or a server fails, then shuts
+// down gracefully: new work is rejected, in-flight batches get the grace
+// period to finish, and HTTP and then gRPC are stopped. It returns every
+// error that stopped a server or cut the shutdown short.
+func (s *APIServer) Run(ctx context.Context) error {
+	// Listen before starting any work, so a port already in use fails the
+	// start instead of leaving a half-running service.
+	serverAddress := fmt.Sprintf("%s:%s", s.cfg.Server.Address, s.cfg.Server.Port)
+	httpListener, err := net.Listen("tcp", serverAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serverAddress, err)
+	}
+	defer httpListener.Close()
+	grpcListener, err := net.Listen("tcp", ":"+s.cfg.Server.GRPCPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", s.cfg.Server.GRPCPort, err)
+	}
+	defer grpcListener.Close()
+
+	// Background work outlives ctx by the grace period, so in-flight
+	// batches are not cut off by the shutdown signal itself.
+	workCtx, stopWork := context.WithCancel(context.Background())
+	defer stopWork()
+
 	mux := http.NewServeMux()
 
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	// Set once the Mindbox client exists; breaker transitions refresh
+	// readiness right away instead of on the next probe.
+	var healthService *service.HealthService
+
 	cfg := &mind_box.ConfigSt{
 		Timeout:            s.cfg.ExternalService.Timeout,
 		Uri:                s.cfg.ExternalService.URI,
 		RetryCount:         5,
 		RetryInterval:      5 * time.Second,
+		RetryMaxInterval:   30 * time.Second,
+		RetryBudget:        10,
+		RetryBudgetRatio:   0.1,
 		InsecureSkipVerify: false,
 
-		MaxRetries:    5,
-		ResetDuration: 15 * time.Second,
+		MaxRetries:     5,
+		ResetDuration:  15 * time.Second,
+		HalfOpenProbes: 1,
+		OnStateChange: func(from, to httpclient.State) {
+			s.logger.Warn("mindbox circuit breaker state changed",
+				slog.String("from", from.String()),
+				slog.String("to", to.String()))
+			if healthService != nil {
+				healthService.Status()
+			}
+		},
 
 		SECRET_KEY: s.cfg.ExternalService.SecretKey,
+
+		RequestsPerSecond: s.cfg.ExternalService.RequestsPerSecond,
+		ItemsPerSecond:    s.cfg.ExternalService.ItemsPerSecond,
+
+		Metrics: httpclient.NewMetrics(registry, "mindbox"),
 	}
 
 	entityProvider, err := mind_box.New(cfg)
 	if err != nil {
 		return err
 	}
-	workerService := service.NewSyncService(s.cfg.WorkerConfig, s.logger, time.Now, entityProvider)
+	healthService = service.NewHealthService(s.cfg.Health, s.logger, time.Now, entityProvider)
+	go healthService.Run(workCtx)
+	healthHandler := handlers.NewHealthHandler(s.logger, healthService)
+	healthHandler.RegisterEndpoints(mux)
+
+	syncOpts := []service.SyncOption{service.WithMetrics(registry)}
+	if s.cfg.Storage.QueueDir != "" {
+		queue, err := filequeue.New(s.cfg.Storage.QueueDir)
+		if err != nil {
+			return err
+		}
+		syncOpts = append(syncOpts, service.WithBatchQueue(queue))
+	}
+
+	var snapshots service.SnapshotStore = snapshot.NewMemory()
+	if s.cfg.Storage.SnapshotFile != "" {
+		fileStore, err := snapshot.NewFile(s.cfg.Storage.SnapshotFile)
+		if err != nil {
+			return err
+		}
+		defer fileStore.Close()
+		snapshots = fileStore
+	}
+	syncOpts = append(syncOpts, service.WithSnapshotStore(snapshots))
+
+	if s.cfg.Cache.Enabled {
+		var store service.PriceCacheStore = pricecache.NewMemory()
+		if s.cfg.Cache.File != "" {
+			fileStore, err := pricecache.NewFile(s.cfg.Cache.File)
+			if err != nil {
+				return err
+			}
+			defer fileStore.Close()
+			store = fileStore
+		}
+		priceCache := service.NewPriceCache(s.cfg.Cache, s.logger, time.Now, store, entityProvider)
+		go priceCache.Run(workCtx)
+		syncOpts = append(syncOpts, service.WithPriceCache(priceCache))
+	}
+
+	if s.cfg.Catalog.Enabled {
+		catalog := service.NewPromotionCatalog(s.cfg.Catalog, s.logger, entityProvider)
+		go catalog.Run(workCtx)
+		syncOpts = append(syncOpts, service.WithPromotionCatalog(catalog))
+	}
+
+	validator, err := service.NewValidator(s.cfg.Validation)
+	if err != nil {
+		return err
+	}
+	syncOpts = append(syncOpts, service.WithValidator(validator))
+
+	workerService := service.NewSyncService(s.cfg.WorkerConfig, s.logger, time.Now, entityProvider, syncOpts...)
 	SessionHandler := handlers.NewWorkerHandler(s.logger, workerService)
 	SessionHandler.RegisterEndpoints(mux)
 
-	go grpc.StartGRPCServer(s.cfg.Server.GRPCPort, workerService, s.logger)
+	jobService := service.NewJobService(s.cfg.JobConfig, s.logger, time.Now, workerService)
+	if err := jobService.Resume(workCtx); err != nil {
+		return err
+	}
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobService.Run(workCtx)
+	}()
+	jobHandler := handlers.NewJobHandler(s.logger, jobService)
+	jobHandler.RegisterEndpoints(mux)
+
+	var schedules []*domain.Schedule
+	if s.cfg.Scheduler.File != "" {
+		schedules, err = schedule.LoadFile(s.cfg.Scheduler.File)
+		if err != nil {
+			return err
+		}
+	}
+	scheduler, err := service.NewScheduler(s.cfg.Scheduler, s.logger, time.Now,
+		jobService, workerService, schedule.NewSource(s.cfg.Scheduler.SourceTimeout), schedules)
+	if err != nil {
+		return err
+	}
+	go scheduler.Run(workCtx)
+	scheduleHandler := handlers.NewScheduleHandler(s.logger, scheduler)
+	scheduleHandler.RegisterEndpoints(mux)
+
+	authenticator, err := service.NewAuthenticator(s.cfg.Auth, time.Now)
+	if err != nil {
+		return err
+	}
 
-	MWChain := middleware.NewMiddlewareChain(middleware.RecoveryMW, middleware.NewTimeoutContextMW(120))
+	unaryMetrics, streamMetrics := grpc.NewMetricsInterceptors(registry)
+	unaryAuth, streamAuth := grpc.NewAuthInterceptors(s.logger, authenticator)
+	grpcServer := grpc.NewGRPCServer(workerService, healthService, s.logger,
+		grpclib.ChainUnaryInterceptor(unaryMetrics, unaryAuth),
+		grpclib.ChainStreamInterceptor(streamMetrics, streamAuth),
+	)
 
-	serverAddress := fmt.Sprintf("%s:%s", s.cfg.Server.Address, s.cfg.Server.Port)
-	s.logger.Info("starting server", slog.String("host", serverAddress))
-	httpServer := http.Server{
+	MWChain := middleware.NewMiddlewareChain(
+		middleware.RecoveryMW,
+		middleware.NewTimeoutContextMW(120),
+		middleware.NewAuthMW(s.logger, authenticator, map[string]domain.Scope{
+			"/data/":      domain.ScopeData,
+			"/jobs/":      domain.ScopeData,
+			"/schedules":  domain.ScopeData,
+			"/batching":   domain.ScopeData,
+			"/promotions": domain.ScopePromotions,
+		}),
+		middleware.NewMetricsMW(registry),
+	)
+	httpServer := &http.Server{
 		Addr:    serverAddress,
 		Handler: MWChain(mux),
 	}
-	return httpServer.ListenAndServe()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		s.logger.Info("starting server", slog.String("host", serverAddress))
+		if err := httpServer.Serve(httpListener); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+	go func() {
+		s.logger.Info("gRPC server listening", slog.String("port", s.cfg.Server.GRPCPort))
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			serveErr <- fmt.Errorf("grpc server: %w", err)
+		}
+	}()
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		s.logger.Info("shutting down", slog.Duration("grace_period", s.cfg.Server.ShutdownGracePeriod))
+	case err := <-serveErr:
+		s.logger.Error("server failed, shutting down", slog.String("error", err.Error()))
+		errs = append(errs, err)
+	}
+
+	graceCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownGracePeriod)
+	defer cancel()
+
+	healthService.Drain()
+	if err := workerService.Drain(graceCtx); err != nil {
+		errs = append(errs, fmt.Errorf("in-flight batches did not finish: %w", err))
+	}
+
+	if err := httpServer.Shutdown(graceCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+		httpServer.Close()
+	}
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		grpcServer.GracefulStop()
+	}()
+	select {
+	case <-grpcStopped:
+	case <-graceCtx.Done():
+		errs = append(errs, fmt.Errorf("grpc shutdown: %w", graceCtx.Err()))
+		grpcServer.Stop()
+		<-grpcStopped
+	}
+
+	stopWork()
+	<-jobsDone
+	s.logger.Info("shutdown complete")
+	return errors.Join(errs...)
 }
@@ -0,0 +1,33 @@
+package domain
+
+// Scope is a part of the API a caller may be allowed to use.
+type Scope string
+
+const (
+	// ScopeData covers price calculations: synchronous, streamed, jobs and
+	// schedules.
+	ScopeData       Scope = "data"
+	ScopePromotions Scope = "promotions"
+	// ScopeAll grants every scope.
+	ScopeAll Scope = "*"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject names the caller: the name of an API key or the sub claim of
+	// a token.
+	Subject string
+	Scopes  []Scope
+}
+
+func (p *Principal) Allows(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+)
+
+// PriceCacheEntry is a Mindbox answer for one product at one base price.
+type PriceCacheEntry struct {
+	Key       string
+	Rep       *ImportModelRep
+	ExpiresAt time.Time
+	// Personal is set for the prices of one customer. They are never
+	// written to disk.
+	Personal bool
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+)
+
+type HealthCheck struct {
+	Name    string
+	Healthy bool
+	Error   string
+}
+
+type HealthStatus struct {
+	Ready     bool
+	Checks    []HealthCheck
+	CheckedAt time.Time
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCanceled  JobStatus = "canceled"
+)
+
+type Job struct {
+	Id            string
+	SubdivisionId string
+	Status        JobStatus
+	Error         string
+
+	TotalItems     int
+	ProcessedItems int
+	FailedItems    int
+	// RejectedItems failed validation and are not part of TotalItems.
+	RejectedItems int
+
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+
+	Run    *SyncRun
+	Result *SyncResult
+}
+
+// Progress returns the share of items already handled, in the range [0, 1].
+func (j *Job) Progress() float64 {
+	if j.Done() {
+		return 1
+	}
+	if j.TotalItems == 0 {
+		return 0
+	}
+	return min(float64(j.ProcessedItems+j.FailedItems)/float64(j.TotalItems), 1)
+}
+
+func (j *Job) Done() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCanceled
+}
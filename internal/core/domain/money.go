@@ -0,0 +1,312 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts that don't name one. Mindbox
+// prices are always in it.
+const DefaultCurrency = "KZT"
+
+// nanosPerUnit is the scale amounts are kept at, the same as the nanos of
+// google.type.Money.
+const nanosPerUnit = 1_000_000_000
+
+var (
+	ErrInvalidMoney  = errors.New("invalid amount")
+	ErrMoneyOverflow = errors.New("amount out of range")
+)
+
+// minorUnits is the number of decimal places of the smallest coin of a
+// currency. Currencies not listed use 2.
+var minorUnits = map[string]int{
+	"KZT": 2,
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+}
+
+// RoundingMode tells how Round treats the digits it drops.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds halves away from zero, like 1C does by default.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds halves to the nearest even digit.
+	RoundHalfEven
+	// RoundDown drops the extra digits, rounding towards zero.
+	RoundDown
+)
+
+// Money is an exact decimal amount with up to 9 decimal places, in the range
+// of about ±9.2 billion units. Amounts are never rounded implicitly with the
+// following exceptions:
+//
+//   - Text with more than 9 decimal places is rounded half up when parsed.
+//   - A float64 is converted through its shortest decimal representation,
+//     so 0.1 becomes exactly 0.1.
+//
+// Use Round or RoundMinor where an amount has to fit a currency. An empty
+// currency stands for DefaultCurrency. In JSON an amount is a plain number,
+// so documents that used float64 prices keep their shape; the currency is
+// not part of it.
+type Money struct {
+	nanos    int64
+	currency string
+}
+
+// NewMoney returns units + nanos/1e9 in currency. nanos must have the same
+// sign as units, as in google.type.Money.
+func NewMoney(units int64, nanos int32, currency string) (Money, error) {
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit ||
+		(units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return Money{}, fmt.Errorf("%w: nanos %d do not match units %d", ErrInvalidMoney, nanos, units)
+	}
+	if units > math.MaxInt64/nanosPerUnit || units < math.MinInt64/nanosPerUnit {
+		return Money{}, ErrMoneyOverflow
+	}
+	whole := units * nanosPerUnit
+	if units >= 0 && int64(nanos) > math.MaxInt64-whole || units <= 0 && int64(nanos) < -math.MaxInt64-whole {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{nanos: whole + int64(nanos), currency: currency}, nil
+}
+
+// ParseMoney parses a decimal like "-1234.50". Exponents are not accepted.
+func ParseMoney(s, currency string) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := false
+	switch {
+	case strings.HasPrefix(text, "-"):
+		negative = true
+		text = text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+	whole, frac, _ := strings.Cut(text, ".")
+	if whole == "" && frac == "" || !digitsOnly(whole) || !digitsOnly(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	var units int64
+	if whole != "" {
+		var err error
+		units, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil || units > math.MaxInt64/nanosPerUnit {
+			return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, s)
+		}
+	}
+	roundUp := false
+	if len(frac) > 9 {
+		roundUp = frac[9] >= '5'
+		frac = frac[:9]
+	}
+	frac += strings.Repeat("0", 9-len(frac))
+	nanos, _ := strconv.ParseInt(frac, 10, 64)
+	if nanos > math.MaxInt64-units*nanosPerUnit {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, s)
+	}
+
+	amount := units*nanosPerUnit + nanos
+	if roundUp {
+		if amount == math.MaxInt64 {
+			return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, s)
+		}
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{nanos: amount, currency: currency}, nil
+}
+
+// MustParseMoney is ParseMoney for constants; it panics on invalid input.
+func MustParseMoney(s, currency string) Money {
+	m, err := ParseMoney(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MoneyFromFloat converts f through its shortest decimal representation.
+func MoneyFromFloat(f float64, currency string) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidMoney, f)
+	}
+	return ParseMoney(strconv.FormatFloat(f, 'f', -1, 64), currency)
+}
+
+func digitsOnly(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) Currency() string {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+// WithCurrency returns the same amount in currency.
+func (m Money) WithCurrency(currency string) Money {
+	m.currency = currency
+	return m
+}
+
+// Units is the whole part of the amount and Nanos the fraction in
+// billionths, with the same sign, as in google.type.Money.
+func (m Money) Units() int64 {
+	return m.nanos / nanosPerUnit
+}
+
+func (m Money) Nanos() int32 {
+	return int32(m.nanos % nanosPerUnit)
+}
+
+// Float64 returns the nearest float64, for callers that can't take an exact
+// amount.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.String(), 64)
+	return f
+}
+
+func (m Money) IsZero() bool {
+	return m.nanos == 0
+}
+
+// Equal reports whether m and o are the same amount in the same currency.
+func (m Money) Equal(o Money) bool {
+	return m.nanos == o.nanos && m.Currency() == o.Currency()
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o,
+// ignoring the currency.
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.nanos < o.nanos:
+		return -1
+	case m.nanos > o.nanos:
+		return 1
+	}
+	return 0
+}
+
+// Add and Sub keep the currency of m. Both amounts must be in the same
+// currency.
+func (m Money) Add(o Money) Money {
+	m.nanos += o.nanos
+	return m
+}
+
+func (m Money) Sub(o Money) Money {
+	m.nanos -= o.nanos
+	return m
+}
+
+// Round rounds m to scale decimal places, 0 to 9.
+func (m Money) Round(scale int, mode RoundingMode) Money {
+	if scale >= 9 {
+		return m
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	step := int64(1)
+	for i := scale; i < 9; i++ {
+		step *= 10
+	}
+	abs := m.nanos
+	if abs < 0 {
+		abs = -abs
+	}
+	rest := abs % step
+	abs -= rest
+	switch mode {
+	case RoundHalfUp:
+		if 2*rest >= step {
+			abs += step
+		}
+	case RoundHalfEven:
+		if 2*rest > step || 2*rest == step && (abs/step)%2 == 1 {
+			abs += step
+		}
+	}
+	if m.nanos < 0 {
+		abs = -abs
+	}
+	m.nanos = abs
+	return m
+}
+
+// RoundMinor rounds m to the minor units of its currency.
+func (m Money) RoundMinor(mode RoundingMode) Money {
+	places, ok := minorUnits[m.Currency()]
+	if !ok {
+		places = 2
+	}
+	return m.Round(places, mode)
+}
+
+// String returns the exact amount without trailing zeros, like "1234.5".
+func (m Money) String() string {
+	abs := m.nanos
+	sign := ""
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+	whole := strconv.FormatInt(abs/nanosPerUnit, 10)
+	frac := abs % nanosPerUnit
+	if frac == 0 {
+		return sign + whole
+	}
+	digits := strings.TrimRight(fmt.Sprintf("%09d", frac), "0")
+	return sign + whole + "." + digits
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string holding one. The currency of m
+// is kept.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	text := string(data)
+	// Exponents are valid JSON numbers, so go through float64 for them.
+	if strings.ContainsAny(text, "eE") {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+		}
+		parsed, err := MoneyFromFloat(f, m.currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+	parsed, err := ParseMoney(text, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		units int64
+		nanos int32
+		err   error
+	}{
+		{in: "0", want: "0"},
+		{in: "1234.50", want: "1234.5", units: 1234, nanos: 500000000},
+		{in: " 12.3 ", want: "12.3", units: 12, nanos: 300000000},
+		{in: "+7", want: "7", units: 7},
+		{in: ".5", want: "0.5", nanos: 500000000},
+		{in: "5.", want: "5", units: 5},
+		{in: "-0.5", want: "-0.5", nanos: -500000000},
+		{in: "-1.000000001", want: "-1.000000001", units: -1, nanos: -1},
+		// Digits past the ninth are rounded half away from zero.
+		{in: "0.0000000005", want: "0.000000001", nanos: 1},
+		{in: "0.0000000004", want: "0"},
+		{in: "-0.0000000005", want: "-0.000000001", nanos: -1},
+		{in: "0.9999999995", want: "1", units: 1},
+		{in: "9223372036.854775807", want: "9223372036.854775807", units: 9223372036, nanos: 854775807},
+		{in: "-9223372036.854775807", want: "-9223372036.854775807", units: -9223372036, nanos: -854775807},
+		{in: "9223372036.854775808", err: ErrMoneyOverflow},
+		{in: "9223372036.8547758075", err: ErrMoneyOverflow},
+		{in: "9223372037", err: ErrMoneyOverflow},
+		{in: "99999999999999999999", err: ErrMoneyOverflow},
+		{in: "", err: ErrInvalidMoney},
+		{in: "-", err: ErrInvalidMoney},
+		{in: ".", err: ErrInvalidMoney},
+		{in: "1e3", err: ErrInvalidMoney},
+		{in: "1.2.3", err: ErrInvalidMoney},
+		{in: "--1", err: ErrInvalidMoney},
+		{in: "1,5", err: ErrInvalidMoney},
+		{in: "NaN", err: ErrInvalidMoney},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in, "")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseMoney(%q) error = %v, want %v", tt.in, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error = %v", tt.in, err)
+			}
+			if got.String() != tt.want || got.Units() != tt.units || got.Nanos() != tt.nanos {
+				t.Errorf("ParseMoney(%q) = %s (%d, %d), want %s (%d, %d)",
+					tt.in, got, got.Units(), got.Nanos(), tt.want, tt.units, tt.nanos)
+			}
+		})
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		name  string
+		units int64
+		nanos int32
+		want  string
+		err   error
+	}{
+		{name: "zero", want: "0"},
+		{name: "positive", units: 1, nanos: 500000000, want: "1.5"},
+		{name: "negative", units: -1, nanos: -500000000, want: "-1.5"},
+		{name: "negative nanos only", nanos: -5, want: "-0.000000005"},
+		{name: "max", units: 9223372036, nanos: 854775807, want: "9223372036.854775807"},
+		{name: "min", units: -9223372036, nanos: -854775807, want: "-9223372036.854775807"},
+		{name: "negative nanos with positive units", units: 1, nanos: -1, err: ErrInvalidMoney},
+		{name: "positive nanos with negative units", units: -1, nanos: 1, err: ErrInvalidMoney},
+		{name: "nanos too large", nanos: 1000000000, err: ErrInvalidMoney},
+		{name: "nanos too small", nanos: -1000000000, err: ErrInvalidMoney},
+		{name: "above max", units: 9223372036, nanos: 854775808, err: ErrMoneyOverflow},
+		{name: "below min", units: -9223372036, nanos: -854775808, err: ErrMoneyOverflow},
+		{name: "units above max", units: 9223372037, err: ErrMoneyOverflow},
+		{name: "units below min", units: -9223372037, err: ErrMoneyOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMoney(tt.units, tt.nanos, "")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("NewMoney(%d, %d) error = %v, want %v", tt.units, tt.nanos, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMoney(%d, %d) error = %v", tt.units, tt.nanos, err)
+			}
+			if got.String() != tt.want || got.Units() != tt.units || got.Nanos() != tt.nanos {
+				t.Errorf("NewMoney(%d, %d) = %s (%d, %d), want %s",
+					tt.units, tt.nanos, got, got.Units(), got.Nanos(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int
+		mode  RoundingMode
+		want  string
+	}{
+		{"1.005", 2, RoundHalfUp, "1.01"},
+		{"1.005", 2, RoundHalfEven, "1"},
+		{"1.015", 2, RoundHalfEven, "1.02"},
+		{"1.025", 2, RoundHalfEven, "1.02"},
+		{"1.0051", 2, RoundHalfEven, "1.01"},
+		{"1.009", 2, RoundDown, "1"},
+		{"-1.005", 2, RoundHalfUp, "-1.01"},
+		{"-1.005", 2, RoundHalfEven, "-1"},
+		{"-1.015", 2, RoundHalfEven, "-1.02"},
+		{"-1.009", 2, RoundDown, "-1"},
+		{"2.5", 0, RoundHalfUp, "3"},
+		{"2.5", 0, RoundHalfEven, "2"},
+		{"3.5", 0, RoundHalfEven, "4"},
+		{"-2.5", 0, RoundHalfEven, "-2"},
+		{"-3.5", 0, RoundHalfEven, "-4"},
+		{"-2.5", 0, RoundHalfUp, "-3"},
+		{"0.4", 0, RoundHalfUp, "0"},
+		{"-0.4", 0, RoundHalfUp, "0"},
+		{"1.5", -1, RoundHalfUp, "2"},
+		{"0.123456789", 9, RoundHalfUp, "0.123456789"},
+		{"0.123456789", 12, RoundDown, "0.123456789"},
+		{"9223372035.995", 2, RoundHalfUp, "9223372036"},
+	}
+	for _, tt := range tests {
+		got := MustParseMoney(tt.in, "USD").Round(tt.scale, tt.mode)
+		if got.String() != tt.want {
+			t.Errorf("%s.Round(%d, %d) = %s, want %s", tt.in, tt.scale, tt.mode, got, tt.want)
+		}
+		if got.Currency() != "USD" {
+			t.Errorf("%s.Round(%d, %d) currency = %s, want USD", tt.in, tt.scale, tt.mode, got.Currency())
+		}
+	}
+}
+
+func TestMoneyRoundMinor(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     string
+	}{
+		{"1.005", "", "1.01"},
+		{"1.005", "KZT", "1.01"},
+		{"-1.005", "", "-1.01"},
+		// Currencies without a known minor unit use 2 places.
+		{"1.005", "XYZ", "1.01"},
+	}
+	for _, tt := range tests {
+		got := MustParseMoney(tt.in, tt.currency).RoundMinor(RoundHalfUp)
+		if got.String() != tt.want {
+			t.Errorf("%s %s RoundMinor = %s, want %s", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
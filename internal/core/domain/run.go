@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+)
+
+// SyncRun is a single GetData invocation as it is recorded in the batch
+// queue. Batches keep the products they cover as an offset into Products,
+// so a resumed run only has to send the ranges without an outcome.
+type SyncRun struct {
+	Id              string
+	SubdivisionId   string
+	CalculationTime time.Time
+	Products        []*BasePrice
+	CreatedAt       time.Time
+	// Delta reuses the last result of every product whose base price did
+	// not change since the previous run.
+	Delta bool
+	// MobilePhone is the customer the run calculates prices for, if any.
+	MobilePhone string
+	// Journaled runs are recorded in the batch queue, so they can be
+	// resumed after a restart. Only runs whose result can still be
+	// fetched afterwards, like jobs, are worth it.
+	Journaled bool
+	// Rejected are the products that failed validation. They are not part
+	// of Products.
+	Rejected []*RejectedPrice
+	// Duplicates repeat a product of Products with the same price. They are
+	// not sent, but get the result of the product they repeat.
+	Duplicates []*BasePrice
+
+	Batches []*SyncBatch
+}
+
+type SyncBatch struct {
+	Seq     int
+	Offset  int
+	Count   int
+	Outcome *BatchOutcome
+}
+
+type BatchOutcome struct {
+	Processed []*ImportModelRep
+	Failed    []*FailedPrice
+	// Rejected is only set on the outcome reporting the products that
+	// failed validation, which comes before every batch.
+	Rejected []*RejectedPrice
+	Error    string
+
+	// CacheHits counts the products answered from the price cache,
+	// SnapshotHits the unchanged products of a delta run and CacheMisses
+	// the ones sent to Mindbox. Coalesced counts the products answered by
+	// a duplicate of the run or by a concurrent run pricing the same
+	// product.
+	CacheHits    int
+	CacheMisses  int
+	SnapshotHits int
+	Coalesced    int
+}
+
+// SyncResult is the combined outcome of every batch of a run.
+type SyncResult struct {
+	Processed []*ImportModelRep
+	Failed    []*FailedPrice
+	Rejected  []*RejectedPrice
+	// Placeholders lists every placeholder of Processed once, with all
+	// products showing it.
+	Placeholders []*PromoPlaceholder
+
+	CacheHits    int
+	CacheMisses  int
+	SnapshotHits int
+	Coalesced    int
+}
+
+func (r *SyncResult) Add(outcome *BatchOutcome) {
+	r.Processed = append(r.Processed, outcome.Processed...)
+	r.Failed = append(r.Failed, outcome.Failed...)
+	r.Rejected = append(r.Rejected, outcome.Rejected...)
+	r.CacheHits += outcome.CacheHits
+	r.CacheMisses += outcome.CacheMisses
+	r.SnapshotHits += outcome.SnapshotHits
+	r.Coalesced += outcome.Coalesced
+}
+
+type BatchSizeStats struct {
+	Current       int
+	Min           int
+	Max           int
+	LatencyTarget time.Duration
+	LastReason    string
+	LastChange    time.Time
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+)
+
+// OverlapPolicy decides what happens when a schedule fires while the job it
+// started last time is still running.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the new run.
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue starts the new run once the running one is done. At most
+	// one run is kept waiting.
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapCancel cancels the running job and starts the new run.
+	OverlapCancel OverlapPolicy = "cancel"
+)
+
+// Schedule syncs the products read from Source for a subdivision whenever
+// Cron fires. Source is a file path or an http(s) URL.
+type Schedule struct {
+	Id            string
+	SubdivisionId string
+	Cron          string
+	Source        string
+	Overlap       OverlapPolicy
+	Delta         bool
+	Paused        bool
+
+	NextRun   *time.Time
+	LastRun   *time.Time
+	LastJobId string
+	LastError string
+	// Queued is set while a run waits for the previous job to finish.
+	Queued bool
+}
+
+// PromotionsRefresh is the state of the periodic promotions refresh.
+type PromotionsRefresh struct {
+	Interval   time.Duration
+	LastRun    *time.Time
+	NextRun    *time.Time
+	Promotions int
+	LastError  string
+}
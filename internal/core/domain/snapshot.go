@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+)
+
+// PriceSnapshot is the last result Mindbox returned for a product in a
+// subdivision, together with the base price it was computed for. Personal
+// prices are kept apart per MobilePhone.
+type PriceSnapshot struct {
+	SubdivisionId string
+	MobilePhone   string
+	ProductId     string
+	BasePrice     Money
+	Rep           *ImportModelRep
+	UpdatedAt     time.Time
+}
@@ -1,31 +1,40 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
 // SubdivisionGetInfo
 
 type SubdivisionGetInfoReq struct {
-	Customer struct {
-		MobilePhone string `json:"mobilePhone"`
-	} `json:"customer"`
-	PointOfContact string `json:"pointOfContact"`
+	// Customer is left out for anonymous calculations.
+	Customer       *SubdivisionGetInfoCustomer `json:"customer,omitempty"`
+	PointOfContact string                      `json:"pointOfContact"`
 	ProductList    struct {
 		CalculationDateTimeUtc string                      `json:"calculationDateTimeUtc"`
 		Items                  []SubdivisionGetInfoReqItem `json:"items"`
 	} `json:"productList"`
 }
 
+type SubdivisionGetInfoCustomer struct {
+	MobilePhone string `json:"mobilePhone"`
+}
+
+// Encode rounds base prices half up to minor units, the precision Mindbox
+// calculates in.
 func (m *SubdivisionGetInfoReq) Encode(src *ImportModelReq) {
 	m.PointOfContact = src.SubdivisionId
+	if src.MobilePhone != "" {
+		m.Customer = &SubdivisionGetInfoCustomer{MobilePhone: src.MobilePhone}
+	}
 	m.ProductList.CalculationDateTimeUtc = (src.CalculationTime.UTC()).Format("2006-01-02 15:04:05")
 	m.ProductList.Items = make([]SubdivisionGetInfoReqItem, 0, len(src.Products))
 
 	for _, i := range src.Products {
 		product := SubdivisionGetInfoReqItem{}
 		product.Product.Ids.Mechtakz = i.ProductId
-		product.BasePricePerItem = i.Price
+		product.BasePricePerItem = i.Price.RoundMinor(RoundHalfUp)
 		m.ProductList.Items = append(m.ProductList.Items, product)
 	}
 }
@@ -44,7 +53,7 @@ type SubdivisionGetInfoReqItem struct {
 			Mechtakz string `json:"mechtakz"`
 		} `json:"ids"`
 	} `json:"product"`
-	BasePricePerItem float64 `json:"basePricePerItem"`
+	BasePricePerItem Money `json:"basePricePerItem"`
 }
 
 type SubdivisionGetInfoRepItem struct {
@@ -53,8 +62,8 @@ type SubdivisionGetInfoRepItem struct {
 			Mechtakz string `json:"mechtakz"`
 		} `json:"ids"`
 	} `json:"product"`
-	BasePricePerItem  float64                       `json:"basePricePerItem"`
-	PriceForCustomer  float64                       `json:"priceForCustomer"`
+	BasePricePerItem  Money                         `json:"basePricePerItem"`
+	PriceForCustomer  Money                         `json:"priceForCustomer"`
 	AppliedPromotions []*SubdivisionGetInfoRepPromo `json:"appliedPromotions"`
 	Placeholders      []*PlaceholderRep             `json:"placeholders"`
 }
@@ -65,26 +74,52 @@ func (m *SubdivisionGetInfoRepItem) Decode() *ImportModelRep {
 			ProductId: m.Product.Ids.Mechtakz,
 			Price:     m.PriceForCustomer,
 		},
+		BasePrice:         m.BasePricePerItem,
+		Discount:          m.BasePricePerItem.Sub(m.PriceForCustomer),
 		Promotions:        []*Promo{},
 		PromoPlaceholders: []*PromoPlaceholder{},
 	}
 
+	accruals := make(map[string]*BalanceAccrual)
 	for _, p := range m.AppliedPromotions {
-		resultPr.Promotions = append(resultPr.Promotions, &Promo{
-			Id:         p.Promotion.Ids.MindboxId,
-			ExternalId: p.Promotion.Ids.ExternalId,
-			Type:       p.Type,
-			Name:       p.Promotion.Name,
-		})
+		promo := &Promo{
+			Id:          p.Promotion.Ids.MindboxId,
+			ExternalId:  p.Promotion.Ids.ExternalId,
+			Type:        p.Type,
+			Name:        p.Promotion.Name,
+			Amount:      p.Amount,
+			GroupingKey: p.GroupingKey,
+		}
+		if systemName := p.BalanceType.Ids.SystemName; systemName != "" {
+			promo.BalanceType = &BalanceType{
+				SystemName: systemName,
+				Name:       p.BalanceType.Name,
+			}
+			accrual, ok := accruals[systemName]
+			if !ok {
+				accrual = &BalanceAccrual{BalanceType: promo.BalanceType}
+				accruals[systemName] = accrual
+				resultPr.Accruals = append(resultPr.Accruals, accrual)
+			}
+			accrual.Amount = accrual.Amount.Add(p.Amount)
+		}
+		resultPr.Promotions = append(resultPr.Promotions, promo)
 	}
 
 	for _, pl := range m.Placeholders {
 		for _, content := range pl.Content {
 			resultPr.PromoPlaceholders = append(resultPr.PromoPlaceholders, &PromoPlaceholder{
-				PhId:    pl.IDs.ExternalID,
-				PromoId: content.Promotion.IDs.MindboxID,
-				Type:    content.Type,
-				Message: content.Message,
+				PhId:       pl.IDs.ExternalID,
+				PromoId:    content.Promotion.IDs.MindboxID,
+				Type:       content.Type,
+				Message:    content.Message,
+				ProductIds: []string{m.Product.Ids.Mechtakz},
+				Promo: &Promo{
+					Id:         content.Promotion.IDs.MindboxID,
+					ExternalId: content.Promotion.IDs.ExternalID,
+					Type:       content.Promotion.Type,
+					Name:       content.Promotion.Name,
+				},
 			})
 		}
 	}
@@ -109,7 +144,7 @@ type SubdivisionGetInfoRepPromo struct {
 		} `json:"ids"`
 		Name string `json:"name"`
 	} `json:"balanceType"`
-	Amount float64 `json:"amount"`
+	Amount Money `json:"amount"`
 }
 
 type PlaceholderRep struct {
@@ -120,7 +155,8 @@ type PlaceholderRep struct {
 		Type      string `json:"type"`
 		Promotion struct {
 			IDs struct {
-				MindboxID int64 `json:"mindboxId"`
+				MindboxID  int64  `json:"mindboxId"`
+				ExternalID string `json:"externalId"`
 			} `json:"ids"`
 			Name string `json:"name"`
 			Type string `json:"type"`
@@ -163,12 +199,39 @@ type ImportModelReq struct {
 	SubdivisionId   string
 	CalculationTime time.Time
 	Products        []*BasePrice
+	// MobilePhone identifies the customer to calculate personal prices
+	// for. Empty means an anonymous calculation.
+	MobilePhone string
 }
 
+// ItemSource tells where the result for a product came from.
+type ItemSource string
+
+const (
+	ItemSourceFresh    ItemSource = "fresh"
+	ItemSourceCache    ItemSource = "cache"
+	ItemSourceSnapshot ItemSource = "snapshot"
+)
+
 type ImportModelRep struct {
-	FinalPrice        *FinalPrice
+	FinalPrice *FinalPrice
+	BasePrice  Money
+	// Discount is BasePrice minus the final price.
+	Discount          Money
 	Promotions        []*Promo
 	PromoPlaceholders []*PromoPlaceholder
+	// Accruals sums the promotions that accrue to a balance, like bonus
+	// points, per balance type.
+	Accruals []*BalanceAccrual
+	Source   ItemSource
+}
+
+// WithSource returns a shallow copy of m marked with source, so results
+// shared through the cache or the snapshot store are never modified.
+func (m *ImportModelRep) WithSource(source ItemSource) *ImportModelRep {
+	rep := *m
+	rep.Source = source
+	return &rep
 }
 
 type ImportPromotionsRep struct {
@@ -181,12 +244,71 @@ type ImportPromotionsRep struct {
 
 type BasePrice struct {
 	ProductId string
-	Price     float64
+	Price     Money
+}
+
+type FailureReason string
+
+const (
+	FailureCircuitOpen         FailureReason = "circuit_open"
+	FailureTimeout             FailureReason = "timeout"
+	FailureCanceled            FailureReason = "canceled"
+	FailureHTTPStatus          FailureReason = "http_status"
+	FailureBadStatus           FailureReason = "bad_status"
+	FailureBadProcessingStatus FailureReason = "bad_processing_status"
+	FailureUnknown             FailureReason = "unknown"
+)
+
+// StatusError is returned when Mindbox answers 2xx but reports a failure in
+// the body. Processing is set when the problem is the processingStatus of the
+// product list rather than the top-level status.
+type StatusError struct {
+	Status     string
+	Processing bool
+}
+
+func (e *StatusError) Error() string {
+	if e.Processing {
+		return fmt.Sprintf("invalid processing status %q", e.Status)
+	}
+	return fmt.Sprintf("bad status %q", e.Status)
+}
+
+// FailedPrice is a product that could not be priced, with the error that
+// Mindbox returned for the batch it was isolated in. Retryable tells the
+// caller whether resubmitting the product unchanged may succeed.
+type FailedPrice struct {
+	*BasePrice
+	Reason        FailureReason
+	HTTPStatus    int
+	Body          string
+	MindboxStatus string
+	Retryable     bool
+	Error         string
+}
+
+type RejectReason string
+
+const (
+	RejectEmptyId      RejectReason = "empty_id"
+	RejectInvalidId    RejectReason = "invalid_id"
+	RejectInvalidPrice RejectReason = "invalid_price"
+	RejectPriceRange   RejectReason = "price_out_of_range"
+	RejectCurrency     RejectReason = "unsupported_currency"
+	RejectDuplicate    RejectReason = "duplicate"
+)
+
+// RejectedPrice is a product that failed validation and was never sent to
+// Mindbox.
+type RejectedPrice struct {
+	*BasePrice
+	Reason RejectReason
+	Error  string
 }
 
 type FinalPrice struct {
 	ProductId string
-	Price     float64
+	Price     Money
 }
 
 type Promo struct {
@@ -197,8 +319,28 @@ type Promo struct {
 	SchemaId   string
 	StartDate  *time.Time
 	EndDate    *time.Time
+	// Amount is the discount per item the promotion gave, or the amount
+	// accrued if BalanceType is set.
+	Amount      Money
+	GroupingKey string
+	BalanceType *BalanceType
+}
+
+// BalanceType is a customer balance, like bonus points, that promotions
+// accrue to.
+type BalanceType struct {
+	SystemName string
+	Name       string
 }
 
+type BalanceAccrual struct {
+	BalanceType *BalanceType
+	Amount      Money
+}
+
+// PromoPlaceholder is a placeholder as shown on one product. ProductIds
+// only holds that product; the products sharing the placeholder are listed
+// once per response by PlaceholderGroups.
 type PromoPlaceholder struct {
 	PhId       string
 	PromoId    int64
@@ -208,3 +350,54 @@ type PromoPlaceholder struct {
 
 	Promo *Promo
 }
+
+type placeholderKey struct {
+	phId    string
+	promoId int64
+	typ     string
+	message string
+}
+
+// PlaceholderGroups collects every distinct placeholder of a response with
+// all products showing it, in the order they first appear. Results can be
+// added batch by batch, so streamed and collected responses group alike.
+type PlaceholderGroups struct {
+	index  map[placeholderKey]*PromoPlaceholder
+	listed map[placeholderKey]map[string]bool
+	groups []*PromoPlaceholder
+}
+
+func (g *PlaceholderGroups) Add(reps []*ImportModelRep) {
+	if g.index == nil {
+		g.index = make(map[placeholderKey]*PromoPlaceholder)
+		g.listed = make(map[placeholderKey]map[string]bool)
+	}
+	for _, rep := range reps {
+		for _, ph := range rep.PromoPlaceholders {
+			key := placeholderKey{ph.PhId, ph.PromoId, ph.Type, ph.Message}
+			group, ok := g.index[key]
+			if !ok {
+				group = &PromoPlaceholder{
+					PhId:       ph.PhId,
+					PromoId:    ph.PromoId,
+					Type:       ph.Type,
+					Message:    ph.Message,
+					ProductIds: []string{},
+					Promo:      ph.Promo,
+				}
+				g.index[key] = group
+				g.listed[key] = make(map[string]bool)
+				g.groups = append(g.groups, group)
+			}
+			if rep.FinalPrice == nil || g.listed[key][rep.FinalPrice.ProductId] {
+				continue
+			}
+			g.listed[key][rep.FinalPrice.ProductId] = true
+			group.ProductIds = append(group.ProductIds, rep.FinalPrice.ProductId)
+		}
+	}
+}
+
+func (g *PlaceholderGroups) List() []*PromoPlaceholder {
+	return g.groups
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+	ErrForbidden       = errors.New("credentials do not grant access")
+)
+
+// Authenticator resolves the credential a caller presented, an API key or
+// a token, to the caller.
+type Authenticator interface {
+	Authenticate(credential string) (*domain.Principal, error)
+}
+
+// NewAuthenticator builds the authenticators enabled in cfg. It returns nil
+// if authentication is disabled.
+func NewAuthenticator(cfg config.Auth, timeSource func() time.Time) (Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	var chain Authenticators
+	if cfg.APIKeys != "" {
+		keys, err := ParseAPIKeys(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+	if cfg.JWTSecret != "" {
+		chain = append(chain, &JWTVerifier{
+			Secret:     []byte(cfg.JWTSecret),
+			Issuer:     cfg.JWTIssuer,
+			Audience:   cfg.JWTAudience,
+			Leeway:     cfg.JWTLeeway,
+			TimeSource: timeSource,
+		})
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("authentication is enabled, but neither API keys nor a JWT secret are set")
+	}
+	return chain, nil
+}
+
+// Authenticators tries every authenticator in turn and returns the first
+// caller one of them accepts.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(credential string) (*domain.Principal, error) {
+	err := ErrUnauthenticated
+	for _, auth := range a {
+		principal, e := auth.Authenticate(credential)
+		if e == nil {
+			return principal, nil
+		}
+		err = e
+	}
+	return nil, err
+}
+
+// Authorize checks that credential is accepted by auth and grants scope. A
+// nil Authenticator lets everyone in.
+func Authorize(auth Authenticator, credential string, scope domain.Scope) (*domain.Principal, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	principal, err := auth.Authenticate(credential)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.Allows(scope) {
+		return principal, fmt.Errorf("%w: %s has no %s scope", ErrForbidden, principal.Subject, scope)
+	}
+	return principal, nil
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller stored by ContextWithPrincipal,
+// or nil if the request was not authenticated.
+func PrincipalFromContext(ctx context.Context) *domain.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*domain.Principal)
+	return principal
+}
+
+// APIKeys accepts static keys, indexed by their SHA-256 so a lookup takes
+// the same time however much of a key is right.
+type APIKeys map[[sha256.Size]byte]*domain.Principal
+
+// ParseAPIKeys parses a comma-separated list of name:key:scopes entries,
+// where scopes are separated by |, like "ci:s3cr3t:data|promotions".
+func ParseAPIKeys(s string) (APIKeys, error) {
+	keys := make(APIKeys)
+	for i, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			// The entry is not quoted, as it may hold a key.
+			return nil, fmt.Errorf("invalid API key entry %d, want name:key:scopes", i+1)
+		}
+		principal := &domain.Principal{Subject: parts[0]}
+		for _, scope := range strings.Split(parts[2], "|") {
+			principal.Scopes = append(principal.Scopes, domain.Scope(strings.TrimSpace(scope)))
+		}
+		hash := sha256.Sum256([]byte(parts[1]))
+		if _, ok := keys[hash]; ok {
+			return nil, fmt.Errorf("API key of %s is used twice", parts[0])
+		}
+		keys[hash] = principal
+	}
+	return keys, nil
+}
+
+func (k APIKeys) Authenticate(credential string) (*domain.Principal, error) {
+	principal, ok := k[sha256.Sum256([]byte(credential))]
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+// JWTVerifier accepts JWTs signed with HS256, HS384 or HS512 and Secret.
+// The exp and nbf claims are checked with Leeway, iss and aud only if
+// Issuer or Audience are set. Scopes come from the space-separated scope
+// claim.
+type JWTVerifier struct {
+	Secret     []byte
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+	TimeSource func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt *int64      `json:"exp"`
+	NotBefore *int64      `json:"nbf"`
+	Scope     string      `json:"scope"`
+}
+
+// jwtAudience is the aud claim, which is either a string or a list.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (v *JWTVerifier) Authenticate(credential string) (*domain.Principal, error) {
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 {
+		return nil, ErrUnauthenticated
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	var newHash func() hash.Hash
+	switch header.Alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		return nil, fmt.Errorf("%w: unsupported token algorithm %q", ErrUnauthenticated, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token signature", ErrUnauthenticated)
+	}
+	mac := hmac.New(newHash, v.Secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: invalid token signature", ErrUnauthenticated)
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	now := time.Now()
+	if v.TimeSource != nil {
+		now = v.TimeSource()
+	}
+	switch {
+	case claims.ExpiresAt != nil && now.After(time.Unix(*claims.ExpiresAt, 0).Add(v.Leeway)):
+		return nil, fmt.Errorf("%w: token expired", ErrUnauthenticated)
+	case claims.NotBefore != nil && now.Before(time.Unix(*claims.NotBefore, 0).Add(-v.Leeway)):
+		return nil, fmt.Errorf("%w: token not valid yet", ErrUnauthenticated)
+	case v.Issuer != "" && claims.Issuer != v.Issuer:
+		return nil, fmt.Errorf("%w: unexpected token issuer", ErrUnauthenticated)
+	case v.Audience != "" && !slices.Contains(claims.Audience, v.Audience):
+		return nil, fmt.Errorf("%w: unexpected token audience", ErrUnauthenticated)
+	}
+
+	principal := &domain.Principal{Subject: claims.Subject}
+	for _, scope := range strings.Fields(claims.Scope) {
+		principal.Scopes = append(principal.Scopes, domain.Scope(scope))
+	}
+	return principal, nil
+}
+
+func decodeJWTPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
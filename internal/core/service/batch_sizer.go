@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+)
+
+// BatchSizer adjusts the batch size between the configured bounds: it halves
+// the size after a timeout or a 5xx response, shrinks it by a quarter when a
+// batch is slower than the latency target and grows it by a tenth while
+// batches stay under the target.
+type BatchSizer struct {
+	logger     *slog.Logger
+	timeSource func() time.Time
+
+	mu     sync.Mutex
+	stats  domain.BatchSizeStats
+	target time.Duration
+}
+
+func NewBatchSizer(cfg config.WorkerConfig, logger *slog.Logger, timeSource func() time.Time) *BatchSizer {
+	initial := int(cfg.BatchSize)
+	minSize := max(int(cfg.BatchSizeMin), 1)
+	maxSize := max(int(cfg.BatchSizeMax), minSize)
+	if initial < 1 {
+		initial = maxSize
+	}
+	initial = min(max(initial, minSize), maxSize)
+
+	return &BatchSizer{
+		logger:     logger,
+		timeSource: timeSource,
+		target:     cfg.BatchLatencyTarget,
+		stats: domain.BatchSizeStats{
+			Current:       initial,
+			Min:           minSize,
+			Max:           maxSize,
+			LatencyTarget: cfg.BatchLatencyTarget,
+		},
+	}
+}
+
+func (b *BatchSizer) Size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats.Current
+}
+
+func (b *BatchSizer) Stats() domain.BatchSizeStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Observe records the outcome of a batch. ctx is the context the batch ran
+// with: errors caused by the caller giving up don't say anything about Mindbox.
+func (b *BatchSizer) Observe(ctx context.Context, latency time.Duration, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	size := b.stats.Current
+	var reason string
+	switch {
+	case err != nil:
+		overload := overloadReason(err)
+		if overload == "" {
+			return
+		}
+		size /= 2
+		reason = overload
+	case b.target <= 0:
+		return
+	case latency > b.target:
+		size -= size / 4
+		reason = fmt.Sprintf("latency %s above target %s", latency.Round(time.Millisecond), b.target)
+	default:
+		size += max(size/10, 1)
+		reason = fmt.Sprintf("latency %s under target %s", latency.Round(time.Millisecond), b.target)
+	}
+
+	size = min(max(size, b.stats.Min), b.stats.Max)
+	if size == b.stats.Current {
+		return
+	}
+
+	b.logger.Info("batch size changed",
+		slog.Int("from", b.stats.Current),
+		slog.Int("to", size),
+		slog.String("reason", reason))
+	b.stats.Current = size
+	b.stats.LastReason = reason
+	b.stats.LastChange = b.timeSource()
+}
+
+// overloadReason describes err if it means Mindbox is struggling with the
+// batch, and returns an empty string otherwise.
+func overloadReason(err error) string {
+	var apiErr *httpclient.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests) {
+		return fmt.Sprintf("status %d", apiErr.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return ""
+}
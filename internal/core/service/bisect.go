@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+)
+
+// bisect retries a rejected batch as two halves, recursing until the halves
+// are accepted or shrink to BisectMinSize. Only the products of the smallest
+// failing halves end up in failed.
+func (s *SyncService) bisect(
+	ctx context.Context,
+	req *domain.ImportModelReq,
+	cause error,
+) (processed []*domain.ImportModelRep, failed []*domain.FailedPrice) {
+	minSize := max(int(s.cfg.BisectMinSize), 1)
+	if len(req.Products) <= minSize || !shouldBisect(ctx, cause) {
+		return nil, newFailedPrices(req.Products, cause)
+	}
+
+	s.logger.Debug("bisecting failed batch",
+		slog.String("subdivision", req.SubdivisionId),
+		slog.Int("size", len(req.Products)),
+		slog.String("error", cause.Error()))
+
+	mid := len(req.Products) / 2
+	for _, products := range [][]*domain.BasePrice{req.Products[:mid], req.Products[mid:]} {
+		half := &domain.ImportModelReq{
+			SubdivisionId:   req.SubdivisionId,
+			CalculationTime: req.CalculationTime,
+			Products:        products,
+			MobilePhone:     req.MobilePhone,
+		}
+		data, err := s.entityDataAPI.GetFinalPriceInfo(ctx, half)
+		if err != nil {
+			p, f := s.bisect(ctx, half, err)
+			processed = append(processed, p...)
+			failed = append(failed, f...)
+			continue
+		}
+		processed = append(processed, data...)
+	}
+	return processed, failed
+}
+
+// shouldBisect reports whether err may have been caused by the contents of
+// the batch. Splitting is pointless when Mindbox is unavailable or
+// overloaded, and it would only add to the load.
+func shouldBisect(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, httpclient.ErrCircuitOpen) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return overloadReason(err) == ""
+}
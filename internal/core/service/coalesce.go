@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// productKey identifies a product by ID and price, the only fields Mindbox
+// prices it by.
+type productKey struct {
+	productId string
+	price     string
+}
+
+func keyOf(product *domain.BasePrice) productKey {
+	return productKey{product.ProductId, product.Price.String()}
+}
+
+// repKey is the key of the product rep answers, by the base price Mindbox
+// echoes back.
+func repKey(rep *domain.ImportModelRep) productKey {
+	return productKey{rep.FinalPrice.ProductId, rep.BasePrice.String()}
+}
+
+// repsByKey indexes reps by product and price, so the same product listed
+// at two prices gets the answer for its own price.
+func repsByKey(reps []*domain.ImportModelRep) map[productKey]*domain.ImportModelRep {
+	byKey := make(map[productKey]*domain.ImportModelRep, len(reps))
+	for _, rep := range reps {
+		if rep.FinalPrice != nil {
+			byKey[repKey(rep)] = rep
+		}
+	}
+	return byKey
+}
+
+// coalesceProducts drops every product that is listed again with the same
+// ID and price, keeping the first occurrence in its place.
+func coalesceProducts(products []*domain.BasePrice) (unique, duplicates []*domain.BasePrice) {
+	seen := make(map[productKey]struct{}, len(products))
+	unique = make([]*domain.BasePrice, 0, len(products))
+	for _, product := range products {
+		key := keyOf(product)
+		if _, ok := seen[key]; ok {
+			duplicates = append(duplicates, product)
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, product)
+	}
+	return unique, duplicates
+}
+
+// fanOut hands the results of the products of a run to the duplicates
+// coalesceProducts dropped from it.
+type fanOut map[productKey][]*domain.BasePrice
+
+func newFanOut(duplicates []*domain.BasePrice) fanOut {
+	f := make(fanOut)
+	for _, product := range duplicates {
+		key := keyOf(product)
+		f[key] = append(f[key], product)
+	}
+	return f
+}
+
+// apply appends a result to outcome for every duplicate of its products.
+// Each duplicate is answered only once.
+func (f fanOut) apply(outcome *domain.BatchOutcome) {
+	if len(f) == 0 {
+		return
+	}
+	for _, rep := range outcome.Processed {
+		if rep.FinalPrice == nil {
+			continue
+		}
+		key := repKey(rep)
+		for range f[key] {
+			outcome.Processed = append(outcome.Processed, rep)
+			outcome.Coalesced++
+		}
+		delete(f, key)
+	}
+	for _, failed := range outcome.Failed {
+		key := keyOf(failed.BasePrice)
+		for _, product := range f[key] {
+			copied := *failed
+			copied.BasePrice = product
+			outcome.Failed = append(outcome.Failed, &copied)
+			outcome.Coalesced++
+		}
+		delete(f, key)
+	}
+}
+
+// flightGroup lets concurrent runs share the Mindbox query of a product:
+// while one run is pricing it, every other run asking for the same product
+// in the same subdivision for the same customer waits for that answer
+// instead of sending its own.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[flightKey]*flight
+}
+
+type flightKey struct {
+	subdivisionId string
+	mobilePhone   string
+	product       productKey
+}
+
+type flight struct {
+	done   chan struct{}
+	rep    *domain.ImportModelRep
+	failed *domain.FailedPrice
+	// abandoned is set when the run pricing the product was canceled, so
+	// the result says nothing about the product.
+	abandoned bool
+}
+
+// waiter is a product of a request whose flight belongs to another run.
+type waiter struct {
+	product *domain.BasePrice
+	flight  *flight
+}
+
+// join starts a flight for every product of req nobody else is pricing yet
+// and returns them as own. The remaining products wait for the flights in
+// progress.
+func (g *flightGroup) join(req *domain.ImportModelReq) (own []*domain.BasePrice, waiters []waiter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights == nil {
+		g.flights = make(map[flightKey]*flight)
+	}
+	for _, product := range req.Products {
+		key := flightKey{req.SubdivisionId, req.MobilePhone, keyOf(product)}
+		if f, ok := g.flights[key]; ok {
+			waiters = append(waiters, waiter{product, f})
+			continue
+		}
+		g.flights[key] = &flight{done: make(chan struct{})}
+		own = append(own, product)
+	}
+	return own, waiters
+}
+
+// land ends the flights join started for the products of req and hands
+// their results to the waiters.
+func (g *flightGroup) land(
+	req *domain.ImportModelReq,
+	data []*domain.ImportModelRep,
+	failed []*domain.FailedPrice,
+	abandoned bool,
+) {
+	reps := repsByKey(data)
+	failures := make(map[productKey]*domain.FailedPrice, len(failed))
+	for _, f := range failed {
+		failures[keyOf(f.BasePrice)] = f
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, product := range req.Products {
+		key := flightKey{req.SubdivisionId, req.MobilePhone, keyOf(product)}
+		f, ok := g.flights[key]
+		if !ok {
+			continue
+		}
+		delete(g.flights, key)
+		f.rep = reps[keyOf(product)]
+		f.failed = failures[keyOf(product)]
+		f.abandoned = abandoned
+		close(f.done)
+	}
+}
+
+// fetchShared prices the products of req, sending only those no other run
+// is pricing at the moment. coalesced counts the products answered by
+// another run. A flight abandoned by its run is taken over.
+func (s *SyncService) fetchShared(
+	ctx context.Context,
+	req *domain.ImportModelReq,
+) (data []*domain.ImportModelRep, failed []*domain.FailedPrice, coalesced int, err error) {
+	pending := req.Products
+	for len(pending) > 0 {
+		sub := *req
+		sub.Products = pending
+		own, waiters := s.flights.join(&sub)
+		pending = nil
+
+		if len(own) > 0 {
+			sub.Products = own
+			d, f, e := s.fetch(ctx, &sub)
+			s.flights.land(&sub, d, f, ctx.Err() != nil)
+			data = append(data, d...)
+			failed = append(failed, f...)
+			if e != nil {
+				err = e
+			}
+		}
+
+		for _, w := range waiters {
+			select {
+			case <-w.flight.done:
+			case <-ctx.Done():
+				failed = append(failed, newFailedPrices([]*domain.BasePrice{w.product}, ctx.Err())...)
+				continue
+			}
+			switch {
+			case w.flight.abandoned:
+				pending = append(pending, w.product)
+			case w.flight.rep != nil:
+				data = append(data, w.flight.rep)
+				coalesced++
+			case w.flight.failed != nil:
+				copied := *w.flight.failed
+				copied.BasePrice = w.product
+				failed = append(failed, &copied)
+				coalesced++
+			}
+		}
+	}
+	return data, failed, coalesced, err
+}
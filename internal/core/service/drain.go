@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDraining is returned for work submitted after Drain was called, and by
+// runs that Drain stopped before all their batches were sent.
+var ErrDraining = errors.New("service is shutting down")
+
+// begin registers a run as in flight. Every successful call must be paired
+// with s.inflight.Done.
+func (s *SyncService) begin() error {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	select {
+	case <-s.draining:
+		return ErrDraining
+	default:
+	}
+	s.inflight.Add(1)
+	return nil
+}
+
+// Drain stops new runs and stops the running ones from cutting new batches,
+// then waits for the batches already sent to Mindbox. It returns ctx.Err()
+// if they don't finish in time.
+func (s *SyncService) Drain(ctx context.Context) error {
+	s.drainMu.Lock()
+	select {
+	case <-s.draining:
+	default:
+		close(s.draining)
+	}
+	s.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+)
+
+// maxBodySnippet limits how much of a Mindbox error body is copied into
+// every failed product.
+const maxBodySnippet = 512
+
+func newFailedPrices(products []*domain.BasePrice, err error) []*domain.FailedPrice {
+	template := classifyFailure(err)
+	result := make([]*domain.FailedPrice, len(products))
+	for i, p := range products {
+		failed := template
+		failed.BasePrice = p
+		result[i] = &failed
+	}
+	return result
+}
+
+func classifyFailure(err error) domain.FailedPrice {
+	failed := domain.FailedPrice{
+		Reason:    domain.FailureUnknown,
+		Retryable: true,
+		Error:     err.Error(),
+	}
+
+	var (
+		apiErr    *httpclient.APIError
+		statusErr *domain.StatusError
+		netErr    net.Error
+	)
+	switch {
+	case errors.Is(err, httpclient.ErrCircuitOpen):
+		failed.Reason = domain.FailureCircuitOpen
+	case errors.Is(err, context.Canceled):
+		failed.Reason = domain.FailureCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		failed.Reason = domain.FailureTimeout
+	case errors.As(err, &apiErr):
+		failed.Reason = domain.FailureHTTPStatus
+		failed.HTTPStatus = apiErr.StatusCode
+		failed.Body = string(apiErr.Body[:min(len(apiErr.Body), maxBodySnippet)])
+		failed.Retryable = apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	case errors.As(err, &statusErr):
+		failed.MindboxStatus = statusErr.Status
+		if statusErr.Processing {
+			failed.Reason = domain.FailureBadProcessingStatus
+		} else {
+			failed.Reason = domain.FailureBadStatus
+		}
+		// Mindbox reports its own outages through the status field; any
+		// other status means the request itself was rejected.
+		failed.Retryable = statusErr.Status == "InternalServerError"
+	}
+	return failed
+}
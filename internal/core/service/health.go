@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+)
+
+type MindboxProbe interface {
+	Ping(ctx context.Context) error
+	CircuitState() httpclient.State
+}
+
+// HealthService decides whether the service is ready to take traffic. The
+// Mindbox probe runs in the background, so readiness checks are cheap and
+// don't add load on Mindbox however often they are polled.
+type HealthService struct {
+	cfg        config.Health
+	logger     *slog.Logger
+	timeSource func() time.Time
+	mindbox    MindboxProbe
+
+	// notifyMu is held from a readiness change until every watcher has
+	// seen it, so watchers see the changes in the order they happened.
+	// It is taken before mu.
+	notifyMu sync.Mutex
+
+	mu        sync.Mutex
+	probe     domain.HealthCheck
+	checkedAt time.Time
+	ready     bool
+	draining  bool
+	watchers  []func(ready bool)
+}
+
+func NewHealthService(
+	cfg config.Health,
+	logger *slog.Logger,
+	timeSource func() time.Time,
+	mindbox MindboxProbe,
+) *HealthService {
+	return &HealthService{
+		cfg:        cfg,
+		logger:     logger,
+		timeSource: timeSource,
+		mindbox:    mindbox,
+		probe:      domain.HealthCheck{Name: "mindbox", Error: "not probed yet"},
+	}
+}
+
+// Watch registers fn to be called with the current readiness and again
+// every time it changes. fn must not call back into the HealthService.
+func (s *HealthService) Watch(fn func(ready bool)) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+
+	s.mu.Lock()
+	s.watchers = append(s.watchers, fn)
+	ready := s.ready
+	s.mu.Unlock()
+
+	fn(ready)
+}
+
+// Run probes Mindbox every ProbeInterval until ctx is done.
+func (s *HealthService) Run(ctx context.Context) {
+	interval := s.cfg.ProbeInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Probe(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Probe pings Mindbox once and updates readiness.
+func (s *HealthService) Probe(ctx context.Context) {
+	if s.cfg.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ProbeTimeout)
+		defer cancel()
+	}
+
+	check := domain.HealthCheck{Name: "mindbox", Healthy: true}
+	if err := s.mindbox.Ping(ctx); err != nil {
+		check.Healthy = false
+		check.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	if check.Healthy != s.probe.Healthy {
+		s.logger.Warn("mindbox probe changed",
+			slog.Bool("healthy", check.Healthy),
+			slog.String("error", check.Error))
+	}
+	s.probe = check
+	s.checkedAt = s.timeSource()
+	s.mu.Unlock()
+
+	s.Status()
+}
+
+// Drain marks the service as not ready for good, so load balancers stop
+// sending traffic while it shuts down.
+func (s *HealthService) Drain() {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	s.Status()
+}
+
+// Status reports the latest probe and the circuit breaker state. The
+// service is not ready while either says Mindbox is unavailable; a
+// half-open breaker is still ready, since its probes need traffic.
+func (s *HealthService) Status() domain.HealthStatus {
+	// The breaker is read under notifyMu too, so the last caller always
+	// reports the latest breaker state.
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+
+	circuit := domain.HealthCheck{Name: "circuit_breaker", Healthy: true}
+	if state := s.mindbox.CircuitState(); state == httpclient.StateOpen {
+		circuit.Healthy = false
+		circuit.Error = "circuit breaker is " + state.String()
+	}
+
+	s.mu.Lock()
+	status := domain.HealthStatus{
+		Ready:     circuit.Healthy && s.probe.Healthy && !s.draining,
+		Checks:    []domain.HealthCheck{circuit, s.probe},
+		CheckedAt: s.checkedAt,
+	}
+	if s.draining {
+		status.Checks = append(status.Checks, domain.HealthCheck{Name: "shutdown", Error: "shutting down"})
+	}
+	var notify []func(bool)
+	if status.Ready != s.ready {
+		s.ready = status.Ready
+		notify = append(notify, s.watchers...)
+	}
+	s.mu.Unlock()
+
+	for _, fn := range notify {
+		fn(status.Ready)
+	}
+	return status
+}
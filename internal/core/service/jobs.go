@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobQueueFull = errors.New("job queue is full")
+)
+
+// JobService runs GetData in the background so callers don't have to keep
+// a connection open for the whole sync. Jobs live in memory until TTL
+// passes after they finish.
+type JobService struct {
+	cfg        config.JobConfig
+	logger     *slog.Logger
+	timeSource func() time.Time
+	sync       *SyncService
+
+	mu       sync.RWMutex
+	jobs     map[string]*domain.Job
+	controls map[string]*jobControl
+	queue    chan *domain.Job
+}
+
+// jobControl lets Cancel and Wait reach a job until it is done.
+type jobControl struct {
+	canceled bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewJobService(
+	cfg config.JobConfig,
+	logger *slog.Logger,
+	timeSource func() time.Time,
+	syncService *SyncService,
+) *JobService {
+	queueSize := int(cfg.QueueSize)
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &JobService{
+		cfg:        cfg,
+		logger:     logger,
+		timeSource: timeSource,
+		sync:       syncService,
+		jobs:       make(map[string]*domain.Job),
+		controls:   make(map[string]*jobControl),
+		queue:      make(chan *domain.Job, queueSize),
+	}
+}
+
+// Run starts the job runners and the cleanup loop. It blocks until ctx is done.
+func (s *JobService) Run(ctx context.Context) {
+	runners := int(s.cfg.Runners)
+	if runners < 1 {
+		runners = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < runners; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job := <-s.queue:
+					s.runJob(ctx, job)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.cleanup()
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+	}
+}
+
+func (s *JobService) Submit(
+	subdivisionId string,
+	calculationTime time.Time,
+	products []*domain.BasePrice,
+	opts ...RunOption,
+) (*domain.Job, error) {
+	s.mu.Lock()
+	if len(s.queue) == cap(s.queue) {
+		s.mu.Unlock()
+		return nil, ErrJobQueueFull
+	}
+	s.mu.Unlock()
+
+	run, err := s.sync.NewRun(subdivisionId, calculationTime, products, append(opts, WithJournal())...)
+	if err != nil {
+		return nil, err
+	}
+	job := newJob(run, s.timeSource())
+
+	s.mu.Lock()
+	select {
+	case s.queue <- job:
+		s.track(job)
+	default:
+		s.mu.Unlock()
+		s.sync.FinishRun(run.Id)
+		return nil, ErrJobQueueFull
+	}
+	snapshot := *job
+	s.mu.Unlock()
+
+	s.logger.Info("job submitted",
+		slog.String("job", job.Id),
+		slog.String("subdivision", subdivisionId),
+		slog.Int("items", len(products)))
+	return &snapshot, nil
+}
+
+// Resume turns runs left unfinished by a previous process into jobs, so their
+// remaining batches are sent and the results can be fetched by run ID.
+func (s *JobService) Resume(ctx context.Context) error {
+	runs, err := s.sync.UnfinishedRuns()
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished runs: %w", err)
+	}
+	// Older journals also recorded synchronous runs, whose caller is gone
+	// and whose result nobody can fetch.
+	jobRuns := runs[:0]
+	for _, run := range runs {
+		if !run.Journaled {
+			s.sync.FinishRun(run.Id)
+			continue
+		}
+		jobRuns = append(jobRuns, run)
+	}
+	runs = jobRuns
+	if len(runs) == 0 {
+		return nil
+	}
+
+	jobs := make([]*domain.Job, len(runs))
+	s.mu.Lock()
+	for i, run := range runs {
+		jobs[i] = newJob(run, run.CreatedAt)
+		s.track(jobs[i])
+	}
+	s.mu.Unlock()
+
+	s.logger.Info("resuming unfinished runs", slog.Int("runs", len(runs)))
+	// Resumed jobs may exceed the queue size, so they are fed in the
+	// background instead of being rejected.
+	go func() {
+		for _, job := range jobs {
+			select {
+			case s.queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Get returns a copy of the job, so the caller can read it while the job
+// keeps running.
+func (s *JobService) Get(id string) (*domain.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Cancel stops a queued or running job. Batches already sent to Mindbox
+// are finished first, use Wait to know when the job is done. Canceling a
+// finished job does nothing.
+func (s *JobService) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return ErrJobNotFound
+	}
+	control, ok := s.controls[id]
+	if !ok {
+		return nil
+	}
+	control.canceled = true
+	if control.cancel != nil {
+		control.cancel()
+	}
+	return nil
+}
+
+// Wait blocks until the job is done or ctx is done.
+func (s *JobService) Wait(ctx context.Context, id string) error {
+	s.mu.RLock()
+	_, exists := s.jobs[id]
+	control, ok := s.controls[id]
+	s.mu.RUnlock()
+
+	if !exists {
+		return ErrJobNotFound
+	}
+	if !ok {
+		return nil
+	}
+	select {
+	case <-control.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track registers a new job. s.mu must be held.
+func (s *JobService) track(job *domain.Job) {
+	s.jobs[job.Id] = job
+	s.controls[job.Id] = &jobControl{done: make(chan struct{})}
+}
+
+func (s *JobService) runJob(parent context.Context, job *domain.Job) {
+	// The job must not depend on the request that created it, so it only
+	// inherits cancellation from the service lifecycle.
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	if s.cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+	}
+
+	s.mu.Lock()
+	control := s.controls[job.Id]
+	defer func() {
+		s.mu.Lock()
+		delete(s.controls, job.Id)
+		s.mu.Unlock()
+		close(control.done)
+	}()
+	if control.canceled {
+		finishedAt := s.timeSource()
+		job.Status = domain.JobStatusCanceled
+		job.FinishedAt = &finishedAt
+		job.Run = nil
+		s.mu.Unlock()
+		s.sync.FinishRun(job.Id)
+		s.logger.Info("job canceled", slog.String("job", job.Id))
+		return
+	}
+	control.cancel = cancel
+	startedAt := s.timeSource()
+	job.Status = domain.JobStatusRunning
+	job.StartedAt = &startedAt
+	s.mu.Unlock()
+
+	s.logger.Info("job started", slog.String("job", job.Id))
+
+	result, err := s.sync.ProcessRun(ctx, job.Run,
+		func(outcome *domain.BatchOutcome) {
+			s.mu.Lock()
+			job.ProcessedItems += len(outcome.Processed)
+			job.FailedItems += len(outcome.Failed)
+			s.mu.Unlock()
+		})
+
+	// On shutdown the run stays in the batch queue and is resumed on the
+	// next start.
+	if parent.Err() == nil && !errors.Is(err, ErrDraining) {
+		s.sync.FinishRun(job.Id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := s.timeSource()
+	job.FinishedAt = &finishedAt
+	job.Run = nil
+	job.Result = result
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if control.canceled && parent.Err() == nil {
+		job.Status = domain.JobStatusCanceled
+		s.logger.Info("job canceled", slog.String("job", job.Id))
+		return
+	}
+	if err != nil {
+		job.Status = domain.JobStatusFailed
+		job.Error = err.Error()
+		s.logger.Error("job failed", slog.String("job", job.Id), slog.String("error", err.Error()))
+		return
+	}
+	job.Status = domain.JobStatusCompleted
+	s.logger.Info("job completed",
+		slog.String("job", job.Id),
+		slog.Int("processed", len(result.Processed)),
+		slog.Int("failed", len(result.Failed)))
+}
+
+func (s *JobService) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeSource()
+	for id, job := range s.jobs {
+		if job.Done() && job.FinishedAt != nil && now.Sub(*job.FinishedAt) > s.cfg.TTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func newJob(run *domain.SyncRun, createdAt time.Time) *domain.Job {
+	return &domain.Job{
+		Id:            run.Id,
+		SubdivisionId: run.SubdivisionId,
+		Status:        domain.JobStatusQueued,
+		TotalItems:    len(run.Products) + len(run.Duplicates),
+		RejectedItems: len(run.Rejected),
+		CreatedAt:     createdAt,
+		Run:           run,
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "worker_pool"
+
+// syncMetrics is nil unless WithMetrics is used; its methods do nothing on a
+// nil receiver.
+type syncMetrics struct {
+	batches       *prometheus.CounterVec
+	items         *prometheus.CounterVec
+	batchDuration prometheus.Histogram
+	workers       prometheus.Gauge
+	workersBusy   prometheus.Gauge
+}
+
+// WithMetrics registers the sync instruments with reg: batches and items per
+// subdivision and outcome, batch latency, the adaptive batch size and how
+// many workers are running and busy.
+func WithMetrics(reg prometheus.Registerer) SyncOption {
+	return func(s *SyncService) {
+		m := &syncMetrics{
+			batches: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "batches_total",
+				Help:      "Batches sent to Mindbox by subdivision and outcome (success, error).",
+			}, []string{"subdivision", "outcome"}),
+			items: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "items_total",
+				Help:      "Products synchronized by subdivision and outcome (processed, failed, rejected).",
+			}, []string{"subdivision", "outcome"}),
+			batchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "batch_duration_seconds",
+				Help:      "Time spent on a batch, including retries and bisection.",
+				Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+			}),
+			workers: prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "workers",
+				Help:      "Workers started by the runs in progress.",
+			}),
+			workersBusy: prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "workers_busy",
+				Help:      "Workers waiting for a Mindbox response.",
+			}),
+		}
+		batchSize := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "batch_size",
+			Help:      "Current adaptive batch size.",
+		}, func() float64 {
+			return float64(s.batchSizer.Size())
+		})
+		reg.MustRegister(m.batches, m.items, m.batchDuration, m.workers, m.workersBusy, batchSize)
+		s.metrics = m
+	}
+}
+
+func (m *syncMetrics) addWorkers(n int) {
+	if m == nil {
+		return
+	}
+	m.workers.Add(float64(n))
+}
+
+func (m *syncMetrics) busy(delta int) {
+	if m == nil {
+		return
+	}
+	m.workersBusy.Add(float64(delta))
+}
+
+func (m *syncMetrics) observeBatch(subdivisionId string, outcome *domain.BatchOutcome, seconds float64) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if outcome.Error != "" {
+		result = "error"
+	}
+	m.batches.WithLabelValues(subdivisionId, result).Inc()
+	m.items.WithLabelValues(subdivisionId, "processed").Add(float64(len(outcome.Processed)))
+	m.items.WithLabelValues(subdivisionId, "failed").Add(float64(len(outcome.Failed)))
+	m.batchDuration.Observe(seconds)
+}
+
+func (m *syncMetrics) observeRejected(subdivisionId string, n int) {
+	if m == nil {
+		return
+	}
+	m.items.WithLabelValues(subdivisionId, "rejected").Add(float64(n))
+}
@@ -0,0 +1,285 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	mind_box "github.com/ExonegeS/mechta-two-weeks/internal/adapters/mindbox"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/mindbox/mindboxmock"
+	"github.com/ExonegeS/mechta-two-weeks/internal/adapters/pricecache"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
+)
+
+// These tests drive SyncService against mindboxmock through the real
+// Mindbox client, so retries and the circuit breaker are the ones the
+// service runs with.
+
+type clientSettings struct {
+	retries     int
+	maxFailures int
+	onChange    func(from, to httpclient.State)
+}
+
+func startMock(t *testing.T, cfg mindboxmock.Config, settings clientSettings) (*mindboxmock.Server, *mind_box.Client) {
+	t.Helper()
+	mock, server := mindboxmock.Start(cfg)
+	t.Cleanup(server.Close)
+
+	client, err := mind_box.New(&mind_box.ConfigSt{
+		Timeout:          5 * time.Second,
+		Uri:              server.URL,
+		RetryCount:       settings.retries,
+		RetryInterval:    time.Millisecond,
+		RetryMaxInterval: time.Millisecond,
+		MaxRetries:       settings.maxFailures,
+		ResetDuration:    time.Minute,
+		HalfOpenProbes:   1,
+		OnStateChange:    settings.onChange,
+	})
+	if err != nil {
+		t.Fatalf("mind_box.New: %v", err)
+	}
+	return mock, client
+}
+
+func newSyncService(client *mind_box.Client, cfg config.WorkerConfig, opts ...service.SyncOption) *service.SyncService {
+	return service.NewSyncService(cfg, discardLogger(), time.Now, client, opts...)
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func products(n int) []*domain.BasePrice {
+	result := make([]*domain.BasePrice, n)
+	for i := range result {
+		result[i] = &domain.BasePrice{
+			ProductId: fmt.Sprintf("p%d", i),
+			Price:     domain.MustParseMoney("1000", ""),
+		}
+	}
+	return result
+}
+
+func TestSyncServiceBisectsRejectedBatch(t *testing.T) {
+	mock, client := startMock(t, mindboxmock.Config{
+		Discount:       0.1,
+		RejectProducts: []string{"p3"},
+	}, clientSettings{retries: 2, maxFailures: 5})
+	s := newSyncService(client, config.WorkerConfig{
+		MaxWorkers:    1,
+		BatchSize:     8,
+		BatchSizeMax:  8,
+		BisectEnabled: true,
+		BisectMinSize: 1,
+	})
+
+	result, err := s.GetData(context.Background(), "sub-1", time.Now(), products(8))
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+
+	if len(result.Processed) != 7 {
+		t.Errorf("processed %d products, want 7", len(result.Processed))
+	}
+	for _, rep := range result.Processed {
+		if got := rep.FinalPrice.Price.String(); got != "900" {
+			t.Errorf("%s final price = %s, want 900", rep.FinalPrice.ProductId, got)
+		}
+	}
+	if len(result.Failed) != 1 {
+		t.Fatalf("failed %d products, want 1", len(result.Failed))
+	}
+	failed := result.Failed[0]
+	if failed.ProductId != "p3" || failed.Reason != domain.FailureHTTPStatus ||
+		failed.HTTPStatus != http.StatusBadRequest || failed.Retryable {
+		t.Errorf("failed = %s %s %d retryable=%t, want p3 %s 400 retryable=false",
+			failed.ProductId, failed.Reason, failed.HTTPStatus, failed.Retryable, domain.FailureHTTPStatus)
+	}
+	// The batch of 8, then both halves of 8, 4 and 2 products. Rejections
+	// are not retried.
+	if got := mock.Stats().Requests; got != 7 {
+		t.Errorf("mock got %d requests, want 7", got)
+	}
+}
+
+func TestSyncServiceRetriesServerErrors(t *testing.T) {
+	mock, client := startMock(t, mindboxmock.Config{
+		ErrorRate:   1,
+		ErrorStatus: http.StatusServiceUnavailable,
+	}, clientSettings{retries: 2, maxFailures: 5})
+	s := newSyncService(client, config.WorkerConfig{
+		MaxWorkers:    1,
+		BatchSize:     4,
+		BatchSizeMax:  4,
+		BisectEnabled: true,
+		BisectMinSize: 1,
+	})
+
+	result, err := s.GetData(context.Background(), "sub-1", time.Now(), products(4))
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if len(result.Processed) != 0 || len(result.Failed) != 4 {
+		t.Fatalf("processed %d and failed %d products, want 0 and 4", len(result.Processed), len(result.Failed))
+	}
+	for _, failed := range result.Failed {
+		if failed.HTTPStatus != http.StatusServiceUnavailable || !failed.Retryable {
+			t.Errorf("%s failed with %d retryable=%t, want 503 retryable=true",
+				failed.ProductId, failed.HTTPStatus, failed.Retryable)
+		}
+	}
+	// One request and two retries; an overloaded Mindbox is not bisected.
+	if got := mock.Stats().Requests; got != 3 {
+		t.Errorf("mock got %d requests, want 3", got)
+	}
+
+	mock.Update(func(cfg *mindboxmock.Config) { cfg.ErrorRate = 0 })
+	result, err = s.GetData(context.Background(), "sub-1", time.Now(), products(4))
+	if err != nil {
+		t.Fatalf("GetData after recovery: %v", err)
+	}
+	if len(result.Processed) != 4 || len(result.Failed) != 0 {
+		t.Errorf("after recovery processed %d and failed %d products, want 4 and 0",
+			len(result.Processed), len(result.Failed))
+	}
+}
+
+func TestSyncServiceStopsAtOpenCircuit(t *testing.T) {
+	var transitions []string
+	mock, client := startMock(t, mindboxmock.Config{
+		ErrorRate: 1,
+	}, clientSettings{
+		maxFailures: 2,
+		onChange: func(from, to httpclient.State) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	s := newSyncService(client, config.WorkerConfig{
+		MaxWorkers:    1,
+		BatchSize:     1,
+		BatchSizeMax:  1,
+		BisectEnabled: true,
+		BisectMinSize: 1,
+	})
+
+	result, err := s.GetData(context.Background(), "sub-1", time.Now(), products(4))
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if len(result.Failed) != 4 {
+		t.Fatalf("failed %d products, want 4", len(result.Failed))
+	}
+	reasons := map[domain.FailureReason]int{}
+	for _, failed := range result.Failed {
+		reasons[failed.Reason]++
+	}
+	if reasons[domain.FailureHTTPStatus] != 2 || reasons[domain.FailureCircuitOpen] != 2 {
+		t.Errorf("failure reasons = %v, want 2 %s and 2 %s",
+			reasons, domain.FailureHTTPStatus, domain.FailureCircuitOpen)
+	}
+	// Batches after the breaker opened never reached Mindbox.
+	if got := mock.Stats().Requests; got != 2 {
+		t.Errorf("mock got %d requests, want 2", got)
+	}
+	if got := client.CircuitState(); got != httpclient.StateOpen {
+		t.Errorf("circuit state = %s, want %s", got, httpclient.StateOpen)
+	}
+	if len(transitions) != 1 || transitions[0] != httpclient.StateClosed.String()+"->"+httpclient.StateOpen.String() {
+		t.Errorf("transitions = %v, want one closed->open", transitions)
+	}
+}
+
+func TestSyncServiceKeepsPersonalPricesOffTheCacheFile(t *testing.T) {
+	const phone = "77011234567"
+	_, client := startMock(t, mindboxmock.Config{Discount: 0.1}, clientSettings{maxFailures: 5})
+
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	store, err := pricecache.NewFile(path)
+	if err != nil {
+		t.Fatalf("pricecache.NewFile: %v", err)
+	}
+	defer store.Close()
+	cache := service.NewPriceCache(config.Cache{Enabled: true, TTL: time.Hour}, discardLogger(), time.Now, store, client)
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	s := newSyncService(client, config.WorkerConfig{
+		MaxWorkers:   1,
+		BatchSize:    4,
+		BatchSizeMax: 4,
+	}, service.WithPriceCache(cache))
+
+	for _, opts := range [][]service.RunOption{{service.WithMobilePhone(phone)}, nil} {
+		if _, err := s.GetData(context.Background(), "sub-1", time.Now(), products(4), opts...); err != nil {
+			t.Fatalf("GetData: %v", err)
+		}
+	}
+	// Personal prices are still cached in memory.
+	result, err := s.GetData(context.Background(), "sub-1", time.Now(), products(4), service.WithMobilePhone(phone))
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if result.CacheHits != 4 {
+		t.Errorf("personal run had %d cache hits, want 4", result.CacheHits)
+	}
+
+	for _, when := range []string{"after Put", "after rewrite"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read cache file: %v", err)
+		}
+		if strings.Contains(string(data), phone) {
+			t.Errorf("cache file holds the customer phone %s", when)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 4 {
+			t.Errorf("cache file has %d lines %s, want 4", lines, when)
+		}
+		if err := store.DeleteExpired(time.Now()); err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+	}
+}
+
+func TestSyncServiceMatchesDuplicatesByPrice(t *testing.T) {
+	mock, client := startMock(t, mindboxmock.Config{Discount: 0.1}, clientSettings{maxFailures: 5})
+	// Without a validator the same product may be listed at two prices.
+	s := newSyncService(client, config.WorkerConfig{
+		MaxWorkers:   1,
+		BatchSize:    4,
+		BatchSizeMax: 4,
+	})
+
+	result, err := s.GetData(context.Background(), "sub-1", time.Now(), []*domain.BasePrice{
+		{ProductId: "p0", Price: domain.MustParseMoney("200", "")},
+		{ProductId: "p0", Price: domain.MustParseMoney("100", "")},
+		{ProductId: "p0", Price: domain.MustParseMoney("100", "")},
+	})
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if result.Coalesced != 1 {
+		t.Errorf("coalesced %d products, want 1", result.Coalesced)
+	}
+	finalPrices := map[string]int{}
+	for _, rep := range result.Processed {
+		finalPrices[rep.BasePrice.String()+"->"+rep.FinalPrice.Price.String()]++
+	}
+	if len(result.Processed) != 3 || finalPrices["200->180"] != 1 || finalPrices["100->90"] != 2 {
+		t.Errorf("final prices = %v, want 200->180 once and 100->90 twice", finalPrices)
+	}
+	if got := mock.Stats().ProductsSeen; got != 2 {
+		t.Errorf("mock priced %d products, want 2", got)
+	}
+}
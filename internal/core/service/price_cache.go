@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// PriceCacheStore holds cache entries. Expiry is checked by PriceCache, the
+// store only has to drop expired entries when asked to.
+type PriceCacheStore interface {
+	Get(key string) (*domain.PriceCacheEntry, bool)
+	Put(entries []*domain.PriceCacheEntry) error
+	DeleteExpired(now time.Time) error
+	Clear() error
+}
+
+// PriceCache remembers the final price Mindbox returned for a product at a
+// base price in a subdivision, per customer for personal prices. Every key
+// includes a hash of the active promotions, so a change in the promotions
+// export invalidates all entries at once, including the ones a file-backed
+// store kept across restarts. Until the promotions were read once, the
+// cache is bypassed.
+type PriceCache struct {
+	cfg        config.Cache
+	logger     *slog.Logger
+	timeSource func() time.Time
+	store      PriceCacheStore
+	api        EntityDataProvider
+
+	mu      sync.RWMutex
+	version string
+}
+
+func NewPriceCache(
+	cfg config.Cache,
+	logger *slog.Logger,
+	timeSource func() time.Time,
+	store PriceCacheStore,
+	api EntityDataProvider,
+) *PriceCache {
+	return &PriceCache{
+		cfg:        cfg,
+		logger:     logger,
+		timeSource: timeSource,
+		store:      store,
+		api:        api,
+	}
+}
+
+func WithPriceCache(cache *PriceCache) SyncOption {
+	return func(s *SyncService) {
+		s.cache = cache
+	}
+}
+
+// Run checks the promotions export every PromotionsInterval and drops
+// expired entries, until ctx is done.
+func (c *PriceCache) Run(ctx context.Context) {
+	interval := c.cfg.PromotionsInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to refresh price cache", slog.String("error", err.Error()))
+		}
+		if err := c.store.DeleteExpired(c.timeSource()); err != nil {
+			c.logger.Error("failed to drop expired prices", slog.String("error", err.Error()))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Refresh reads the promotions export and invalidates the cache if it
+// changed since the last call.
+func (c *PriceCache) Refresh(ctx context.Context) error {
+	promotions, err := c.api.GetPromotionsInfo(ctx)
+	if err != nil {
+		return err
+	}
+	return c.update(promotions)
+}
+
+// update invalidates the cache if promotions differ from the ones seen last.
+func (c *PriceCache) update(promotions []*domain.ImportPromotionsRep) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(promotions)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:8])
+
+	c.mu.Lock()
+	previous := c.version
+	c.version = version
+	c.mu.Unlock()
+
+	if previous == version {
+		return nil
+	}
+	if previous != "" {
+		c.logger.Info("promotions changed, price cache invalidated",
+			slog.String("from", previous),
+			slog.String("to", version))
+		return c.store.Clear()
+	}
+	c.logger.Info("price cache enabled", slog.String("promotions", version))
+	return nil
+}
+
+// lookup returns the cached answer for product. It always misses on a nil
+// cache.
+func (c *PriceCache) lookup(subdivisionId, mobilePhone string, product *domain.BasePrice) (*domain.ImportModelRep, bool) {
+	key, ok := c.key(subdivisionId, mobilePhone, product)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := c.store.Get(key)
+	if !ok || !c.timeSource().Before(entry.ExpiresAt) {
+		return nil, false
+	}
+	return entry.Rep.WithSource(domain.ItemSourceCache), true
+}
+
+// save caches the answers Mindbox returned for products.
+func (c *PriceCache) save(subdivisionId, mobilePhone string, products []*domain.BasePrice, reps []*domain.ImportModelRep) {
+	if c == nil || len(reps) == 0 {
+		return
+	}
+	byId := repsByProduct(reps)
+	expiresAt := c.timeSource().Add(c.cfg.TTL)
+	entries := make([]*domain.PriceCacheEntry, 0, len(reps))
+	for _, product := range products {
+		rep, ok := byId[product.ProductId]
+		if !ok {
+			continue
+		}
+		key, ok := c.key(subdivisionId, mobilePhone, product)
+		if !ok {
+			return
+		}
+		entries = append(entries, &domain.PriceCacheEntry{
+			Key:       key,
+			Rep:       rep,
+			ExpiresAt: expiresAt,
+			Personal:  mobilePhone != "",
+		})
+	}
+	if err := c.store.Put(entries); err != nil {
+		c.logger.Error("failed to cache prices", slog.String("error", err.Error()))
+	}
+}
+
+func (c *PriceCache) key(subdivisionId, mobilePhone string, product *domain.BasePrice) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.RLock()
+	version := c.version
+	c.mu.RUnlock()
+	if version == "" {
+		return "", false
+	}
+	return strings.Join([]string{
+		version,
+		subdivisionId,
+		mobilePhone,
+		product.ProductId,
+		product.Price.String(),
+	}, "|"), true
+}
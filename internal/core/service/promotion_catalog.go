@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// PromotionCatalog keeps the promotions export indexed by external ID, so
+// the promotions Mindbox applies to a product can be completed with their
+// schema and validity dates. Until the export was read once, promotions are
+// passed through as they are.
+type PromotionCatalog struct {
+	cfg    config.PromotionCatalog
+	logger *slog.Logger
+	api    EntityDataProvider
+
+	mu         sync.RWMutex
+	promotions map[string]*domain.ImportPromotionsRep
+}
+
+func NewPromotionCatalog(
+	cfg config.PromotionCatalog,
+	logger *slog.Logger,
+	api EntityDataProvider,
+) *PromotionCatalog {
+	return &PromotionCatalog{
+		cfg:    cfg,
+		logger: logger,
+		api:    api,
+	}
+}
+
+func WithPromotionCatalog(catalog *PromotionCatalog) SyncOption {
+	return func(s *SyncService) {
+		s.catalog = catalog
+	}
+}
+
+// Run reads the promotions export every RefreshInterval until ctx is done.
+func (c *PromotionCatalog) Run(ctx context.Context) {
+	interval := c.cfg.RefreshInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to refresh promotion catalog", slog.String("error", err.Error()))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *PromotionCatalog) Refresh(ctx context.Context) error {
+	promotions, err := c.api.GetPromotionsInfo(ctx)
+	if err != nil {
+		return err
+	}
+	c.update(promotions)
+	return nil
+}
+
+// update replaces the catalog with promotions.
+func (c *PromotionCatalog) update(promotions []*domain.ImportPromotionsRep) {
+	if c == nil {
+		return
+	}
+	byId := make(map[string]*domain.ImportPromotionsRep, len(promotions))
+	for _, promo := range promotions {
+		if promo.ExternalID != "" {
+			byId[promo.ExternalID] = promo
+		}
+	}
+
+	c.mu.Lock()
+	c.promotions = byId
+	c.mu.Unlock()
+
+	c.logger.Debug("promotion catalog refreshed", slog.Int("promotions", len(byId)))
+}
+
+// enrich returns copies of reps whose promotions and placeholders are
+// completed from the catalog. reps themselves are never modified, as they
+// may be shared through the price cache or the snapshot store.
+func (c *PromotionCatalog) enrich(reps []*domain.ImportModelRep) []*domain.ImportModelRep {
+	var promotions map[string]*domain.ImportPromotionsRep
+	if c != nil {
+		c.mu.RLock()
+		promotions = c.promotions
+		c.mu.RUnlock()
+	}
+
+	// Placeholders only carry the Mindbox ID of their promotion when
+	// Mindbox leaves the external ID out, so it is looked up among the
+	// applied promotions.
+	externalIds := make(map[int64]string)
+	for _, rep := range reps {
+		for _, promo := range rep.Promotions {
+			if promo.ExternalId != "" {
+				externalIds[promo.Id] = promo.ExternalId
+			}
+		}
+	}
+
+	result := make([]*domain.ImportModelRep, len(reps))
+	for i, rep := range reps {
+		enriched := *rep
+		enriched.Promotions = make([]*domain.Promo, len(rep.Promotions))
+		for j, promo := range rep.Promotions {
+			enriched.Promotions[j] = enrichPromo(promo, promotions)
+		}
+		enriched.PromoPlaceholders = make([]*domain.PromoPlaceholder, len(rep.PromoPlaceholders))
+		for j, ph := range rep.PromoPlaceholders {
+			placeholder := *ph
+			promo := domain.Promo{Id: ph.PromoId}
+			if ph.Promo != nil {
+				promo = *ph.Promo
+			}
+			if promo.ExternalId == "" {
+				promo.ExternalId = externalIds[promo.Id]
+			}
+			placeholder.Promo = enrichPromo(&promo, promotions)
+			enriched.PromoPlaceholders[j] = &placeholder
+		}
+		result[i] = &enriched
+	}
+	return result
+}
+
+func enrichPromo(promo *domain.Promo, promotions map[string]*domain.ImportPromotionsRep) *domain.Promo {
+	enriched := *promo
+	if export, ok := promotions[promo.ExternalId]; ok {
+		enriched.SchemaId = export.SchemaID
+		enriched.StartDate = export.StartDate
+		enriched.EndDate = export.EndDate
+		if enriched.Name == "" {
+			enriched.Name = export.Name
+		}
+	}
+	return &enriched
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// BatchQueue persists runs and their batches so work that was in flight when
+// the process died can be picked up again on the next start.
+type BatchQueue interface {
+	Begin(run *domain.SyncRun) error
+	Enqueue(runId string, batch *domain.SyncBatch) error
+	Complete(runId string, batch *domain.SyncBatch) error
+	Finish(runId string) error
+	Unfinished() ([]*domain.SyncRun, error)
+}
+
+type SyncOption func(*SyncService)
+
+func WithBatchQueue(queue BatchQueue) SyncOption {
+	return func(s *SyncService) {
+		s.queue = queue
+	}
+}
+
+// NewRun validates products and registers a new run in the batch queue if
+// it is journaled and a queue is configured. Invalid products end up in
+// run.Rejected, and products listed again with the same price in
+// run.Duplicates. It fails with ErrDraining once Drain was called and with
+// ErrTooManyItems if the list is too long.
+func (s *SyncService) NewRun(
+	subdivisionId string,
+	calculationTime time.Time,
+	products []*domain.BasePrice,
+	opts ...RunOption,
+) (*domain.SyncRun, error) {
+	select {
+	case <-s.draining:
+		return nil, ErrDraining
+	default:
+	}
+	products, rejected, err := s.validator.Validate(products)
+	if err != nil {
+		return nil, err
+	}
+	products, duplicates := coalesceProducts(products)
+	run := &domain.SyncRun{
+		Id:              newRunID(),
+		SubdivisionId:   subdivisionId,
+		CalculationTime: calculationTime,
+		Products:        products,
+		Duplicates:      duplicates,
+		CreatedAt:       s.timeSource(),
+	}
+	for _, opt := range opts {
+		opt(run)
+	}
+	run.Rejected = append(run.Rejected, rejected...)
+	if s.journaled(run) {
+		if err := s.queue.Begin(run); err != nil {
+			return nil, fmt.Errorf("failed to persist run: %w", err)
+		}
+	}
+	return run, nil
+}
+
+// WithJournal records the run in the batch queue, so it is resumed after
+// a restart until FinishRun is called. Runs for a customer are never
+// journaled, to keep their mobile phone off the disk.
+func WithJournal() RunOption {
+	return func(run *domain.SyncRun) {
+		run.Journaled = true
+	}
+}
+
+func (s *SyncService) journaled(run *domain.SyncRun) bool {
+	return s.queue != nil && run.Journaled && run.MobilePhone == ""
+}
+
+// FinishRun drops the run from the batch queue. Runs that are never finished
+// are returned by UnfinishedRuns after a restart.
+func (s *SyncService) FinishRun(runId string) {
+	if s.queue == nil {
+		return
+	}
+	if err := s.queue.Finish(runId); err != nil {
+		s.logger.Error("failed to finish run", slog.String("run", runId), slog.String("error", err.Error()))
+	}
+}
+
+func (s *SyncService) UnfinishedRuns() ([]*domain.SyncRun, error) {
+	if s.queue == nil {
+		return nil, nil
+	}
+	runs, err := s.queue.Unfinished()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.Before(runs[j].CreatedAt)
+	})
+	return runs, nil
+}
+
+// pendingRanges returns the [offset, offset+count) ranges of run.Products
+// that are not covered by a completed batch.
+func pendingRanges(run *domain.SyncRun) [][2]int {
+	done := make([]bool, len(run.Products))
+	for _, b := range run.Batches {
+		if b.Outcome == nil {
+			continue
+		}
+		for i := b.Offset; i < b.Offset+b.Count && i < len(done); i++ {
+			done[i] = true
+		}
+	}
+
+	var ranges [][2]int
+	start := -1
+	for i, d := range done {
+		switch {
+		case !d && start < 0:
+			start = i
+		case d && start >= 0:
+			ranges = append(ranges, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ranges = append(ranges, [2]int{start, len(done)})
+	}
+	return ranges
+}
+
+func newRunID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
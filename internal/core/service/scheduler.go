@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/cron"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrScheduleBusy     = errors.New("previous run of the schedule is still in progress")
+)
+
+// ProductSource reads the products a schedule syncs from its Source.
+type ProductSource interface {
+	Load(ctx context.Context, location string) ([]*domain.BasePrice, error)
+}
+
+// Scheduler starts a job for every schedule whenever its cron expression
+// fires, and refreshes the promotions every PromotionsInterval. Paused
+// schedules keep their place but don't start jobs until resumed; pausing is
+// not persisted across restarts.
+type Scheduler struct {
+	cfg        config.Scheduler
+	logger     *slog.Logger
+	timeSource func() time.Time
+	jobs       *JobService
+	sync       *SyncService
+	source     ProductSource
+
+	ids       []string
+	schedules map[string]*scheduleState
+
+	mu         sync.Mutex
+	ctx        context.Context
+	promotions domain.PromotionsRefresh
+	wg         sync.WaitGroup
+}
+
+type scheduleState struct {
+	spec cron.Schedule
+	// fireMu makes sure only one run of the schedule is being started at
+	// a time.
+	fireMu sync.Mutex
+
+	mu       sync.Mutex
+	schedule domain.Schedule
+}
+
+func NewScheduler(
+	cfg config.Scheduler,
+	logger *slog.Logger,
+	timeSource func() time.Time,
+	jobs *JobService,
+	syncService *SyncService,
+	source ProductSource,
+	schedules []*domain.Schedule,
+) (*Scheduler, error) {
+	s := &Scheduler{
+		cfg:        cfg,
+		logger:     logger,
+		timeSource: timeSource,
+		jobs:       jobs,
+		sync:       syncService,
+		source:     source,
+		schedules:  make(map[string]*scheduleState, len(schedules)),
+		ctx:        context.Background(),
+		promotions: domain.PromotionsRefresh{Interval: cfg.PromotionsInterval},
+	}
+	for _, schedule := range schedules {
+		if err := validateSchedule(schedule); err != nil {
+			return nil, err
+		}
+		if _, ok := s.schedules[schedule.Id]; ok {
+			return nil, fmt.Errorf("schedule %s is defined twice", schedule.Id)
+		}
+		spec, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", schedule.Id, err)
+		}
+		state := &scheduleState{spec: spec, schedule: *schedule}
+		if state.schedule.Overlap == "" {
+			state.schedule.Overlap = domain.OverlapSkip
+		}
+		s.ids = append(s.ids, schedule.Id)
+		s.schedules[schedule.Id] = state
+	}
+	return s, nil
+}
+
+func validateSchedule(schedule *domain.Schedule) error {
+	switch {
+	case schedule.Id == "":
+		return errors.New("schedule without id")
+	case schedule.SubdivisionId == "":
+		return fmt.Errorf("schedule %s: subdivision is required", schedule.Id)
+	case schedule.Source == "":
+		return fmt.Errorf("schedule %s: source is required", schedule.Id)
+	}
+	switch schedule.Overlap {
+	case "", domain.OverlapSkip, domain.OverlapQueue, domain.OverlapCancel:
+		return nil
+	}
+	return fmt.Errorf("schedule %s: unknown overlap policy %q", schedule.Id, schedule.Overlap)
+}
+
+// Run fires the schedules until ctx is done. Runs waiting for a previous job
+// are dropped when ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	for _, id := range s.ids {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runSchedule(ctx, s.schedules[id])
+		}()
+	}
+	if s.cfg.PromotionsInterval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runPromotions(ctx)
+		}()
+	}
+	s.logger.Info("scheduler started", slog.Int("schedules", len(s.ids)))
+
+	<-ctx.Done()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, state *scheduleState) {
+	for {
+		now := s.timeSource()
+		next := state.spec.Next(now)
+		if next.IsZero() {
+			s.logger.Error("schedule never fires", slog.String("schedule", state.get().Id))
+			return
+		}
+		state.update(func(schedule *domain.Schedule) {
+			schedule.NextRun = &next
+		})
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		if state.get().Paused {
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.fire(ctx, state)
+		}()
+	}
+}
+
+// fire is start for runs nobody waits on, so failures are only logged.
+func (s *Scheduler) fire(ctx context.Context, state *scheduleState) error {
+	err := s.start(ctx, state)
+	if err != nil {
+		s.logger.Error("scheduled run not started",
+			slog.String("schedule", state.get().Id),
+			slog.String("error", err.Error()))
+	}
+	return err
+}
+
+// start loads the products of the schedule and submits a job for them,
+// applying the overlap policy if the job of the previous run is still going.
+func (s *Scheduler) start(ctx context.Context, state *scheduleState) error {
+	if !state.fireMu.TryLock() {
+		return ErrScheduleBusy
+	}
+	defer state.fireMu.Unlock()
+
+	schedule := state.get()
+	if previous := schedule.LastJobId; previous != "" {
+		job, err := s.jobs.Get(previous)
+		if err == nil && !job.Done() {
+			switch schedule.Overlap {
+			case domain.OverlapQueue:
+				return s.queue(state, previous)
+			case domain.OverlapCancel:
+				s.logger.Info("canceling previous scheduled run",
+					slog.String("schedule", schedule.Id),
+					slog.String("job", previous))
+				if err := s.jobs.Cancel(previous); err != nil {
+					return err
+				}
+				if err := s.jobs.Wait(ctx, previous); err != nil {
+					return err
+				}
+			default:
+				return ErrScheduleBusy
+			}
+		}
+	}
+
+	now := s.timeSource()
+	products, err := s.source.Load(ctx, schedule.Source)
+	var job *domain.Job
+	if err == nil {
+		job, err = s.jobs.Submit(schedule.SubdivisionId, now, products, WithDelta(schedule.Delta))
+	}
+	state.update(func(schedule *domain.Schedule) {
+		schedule.LastRun = &now
+		schedule.LastError = ""
+		if err != nil {
+			schedule.LastError = err.Error()
+			return
+		}
+		schedule.LastJobId = job.Id
+	})
+	if err != nil {
+		return err
+	}
+	s.logger.Info("scheduled run started",
+		slog.String("schedule", schedule.Id),
+		slog.String("job", job.Id),
+		slog.Int("items", len(products)))
+	return nil
+}
+
+// queue starts a run of the schedule once the job jobId is done.
+func (s *Scheduler) queue(state *scheduleState, jobId string) error {
+	var queued bool
+	state.update(func(schedule *domain.Schedule) {
+		queued = schedule.Queued
+		schedule.Queued = true
+	})
+	if queued {
+		return ErrScheduleBusy
+	}
+
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.jobs.Wait(ctx, jobId)
+		state.update(func(schedule *domain.Schedule) {
+			schedule.Queued = false
+		})
+		if err == nil {
+			s.fire(ctx, state)
+		}
+	}()
+	s.logger.Info("scheduled run queued",
+		slog.String("schedule", state.get().Id),
+		slog.String("after", jobId))
+	return nil
+}
+
+func (s *Scheduler) runPromotions(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PromotionsInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshPromotions(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) refreshPromotions(ctx context.Context) {
+	now := s.timeSource()
+	next := now.Add(s.cfg.PromotionsInterval)
+	promotions, err := s.sync.RefreshPromotions(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.promotions.LastRun = &now
+	s.promotions.NextRun = &next
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to refresh promotions", slog.String("error", err.Error()))
+		}
+		s.promotions.LastError = err.Error()
+		return
+	}
+	s.promotions.LastError = ""
+	s.promotions.Promotions = len(promotions)
+}
+
+// Trigger starts a run of the schedule right away, whether or not it is
+// paused. The overlap policy applies as for runs started by the schedule.
+func (s *Scheduler) Trigger(ctx context.Context, id string) (*domain.Schedule, error) {
+	state, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	if err := s.start(ctx, state); err != nil {
+		return nil, err
+	}
+	schedule := state.get()
+	return &schedule, nil
+}
+
+func (s *Scheduler) Pause(id string) (*domain.Schedule, error) {
+	return s.setPaused(id, true)
+}
+
+func (s *Scheduler) Resume(id string) (*domain.Schedule, error) {
+	return s.setPaused(id, false)
+}
+
+func (s *Scheduler) setPaused(id string, paused bool) (*domain.Schedule, error) {
+	state, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	state.update(func(schedule *domain.Schedule) {
+		schedule.Paused = paused
+	})
+	s.logger.Info("schedule updated", slog.String("schedule", id), slog.Bool("paused", paused))
+	schedule := state.get()
+	return &schedule, nil
+}
+
+func (s *Scheduler) Get(id string) (*domain.Schedule, error) {
+	state, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	schedule := state.get()
+	return &schedule, nil
+}
+
+// List returns the schedules in the order they were defined.
+func (s *Scheduler) List() []*domain.Schedule {
+	schedules := make([]*domain.Schedule, len(s.ids))
+	for i, id := range s.ids {
+		schedule := s.schedules[id].get()
+		schedules[i] = &schedule
+	}
+	return schedules
+}
+
+func (s *Scheduler) Promotions() domain.PromotionsRefresh {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.promotions
+}
+
+func (st *scheduleState) get() domain.Schedule {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.schedule
+}
+
+func (st *scheduleState) update(f func(schedule *domain.Schedule)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	f(&st.schedule)
+}
@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/service"
+)
+
+// blockingProvider answers price requests only once release is closed, or
+// fails them when their context is done.
+type blockingProvider struct {
+	release chan struct{}
+}
+
+func (p *blockingProvider) GetFinalPriceInfo(ctx context.Context, req *domain.ImportModelReq) ([]*domain.ImportModelRep, error) {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	reps := make([]*domain.ImportModelRep, len(req.Products))
+	for i, product := range req.Products {
+		reps[i] = &domain.ImportModelRep{
+			FinalPrice: &domain.FinalPrice{ProductId: product.ProductId, Price: product.Price},
+			BasePrice:  product.Price,
+		}
+	}
+	return reps, nil
+}
+
+func (p *blockingProvider) GetPromotionsInfo(ctx context.Context) ([]*domain.ImportPromotionsRep, error) {
+	return nil, nil
+}
+
+func (p *blockingProvider) GetExportData(ctx context.Context, operation string) (*domain.PromotionsGetInfoRepSt, error) {
+	return nil, errors.New("not supported")
+}
+
+type staticSource []*domain.BasePrice
+
+func (s staticSource) Load(ctx context.Context, location string) ([]*domain.BasePrice, error) {
+	return s, nil
+}
+
+// newScheduler returns a scheduler with a single schedule "nightly" that
+// only runs when triggered, and the job service running its jobs.
+func newScheduler(t *testing.T, overlap domain.OverlapPolicy, provider *blockingProvider) (*service.Scheduler, *service.JobService) {
+	t.Helper()
+	logger := discardLogger()
+	syncService := service.NewSyncService(config.WorkerConfig{MaxWorkers: 1, BatchSize: 10, BatchSizeMax: 10},
+		logger, time.Now, provider)
+	jobs := service.NewJobService(config.JobConfig{Runners: 1, QueueSize: 4, TTL: time.Hour}, logger, time.Now, syncService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobs.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	scheduler, err := service.NewScheduler(config.Scheduler{}, logger, time.Now, jobs, syncService,
+		staticSource(products(3)), []*domain.Schedule{{
+			Id:            "nightly",
+			SubdivisionId: "sub-1",
+			Cron:          "0 3 * * *",
+			Source:        "products.json",
+			Overlap:       overlap,
+		}})
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+	return scheduler, jobs
+}
+
+func waitJob(t *testing.T, jobs *service.JobService, id string) *domain.Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := jobs.Wait(ctx, id); err != nil {
+		t.Fatalf("Wait(%s): %v", id, err)
+	}
+	job, err := jobs.Get(id)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	return job
+}
+
+func TestSchedulerOverlapSkip(t *testing.T) {
+	provider := &blockingProvider{release: make(chan struct{})}
+	scheduler, jobs := newScheduler(t, domain.OverlapSkip, provider)
+
+	first, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	if _, err := scheduler.Trigger(context.Background(), "nightly"); !errors.Is(err, service.ErrScheduleBusy) {
+		t.Fatalf("Trigger while running: %v, want %v", err, service.ErrScheduleBusy)
+	}
+
+	close(provider.release)
+	if job := waitJob(t, jobs, first.LastJobId); job.Status != domain.JobStatusCompleted {
+		t.Errorf("job status = %s, want %s", job.Status, domain.JobStatusCompleted)
+	}
+	schedule, _ := scheduler.Get("nightly")
+	if schedule.LastJobId != first.LastJobId {
+		t.Errorf("last job = %s, want %s; the skipped run must not start a job", schedule.LastJobId, first.LastJobId)
+	}
+
+	// Once the job is done the schedule runs again.
+	second, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger after the job finished: %v", err)
+	}
+	if second.LastJobId == first.LastJobId {
+		t.Error("no new job started after the previous one finished")
+	}
+}
+
+func TestSchedulerOverlapQueue(t *testing.T) {
+	provider := &blockingProvider{release: make(chan struct{})}
+	scheduler, jobs := newScheduler(t, domain.OverlapQueue, provider)
+
+	first, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	queued, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger while running: %v", err)
+	}
+	if !queued.Queued || queued.LastJobId != first.LastJobId {
+		t.Errorf("schedule queued=%t last job=%s, want a queued run after %s", queued.Queued, queued.LastJobId, first.LastJobId)
+	}
+	// At most one run waits.
+	if _, err := scheduler.Trigger(context.Background(), "nightly"); !errors.Is(err, service.ErrScheduleBusy) {
+		t.Fatalf("Trigger with a run queued: %v, want %v", err, service.ErrScheduleBusy)
+	}
+
+	close(provider.release)
+	waitJob(t, jobs, first.LastJobId)
+
+	deadline := time.Now().Add(5 * time.Second)
+	var schedule *domain.Schedule
+	for {
+		schedule, _ = scheduler.Get("nightly")
+		if schedule.LastJobId != first.LastJobId && !schedule.Queued {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued run did not start: last job %s, queued %t", schedule.LastJobId, schedule.Queued)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job := waitJob(t, jobs, schedule.LastJobId); job.Status != domain.JobStatusCompleted {
+		t.Errorf("queued job status = %s, want %s", job.Status, domain.JobStatusCompleted)
+	}
+}
+
+func TestSchedulerOverlapCancel(t *testing.T) {
+	// The first job is held by the provider, so only canceling ends it.
+	provider := &blockingProvider{release: make(chan struct{})}
+	scheduler, jobs := newScheduler(t, domain.OverlapCancel, provider)
+
+	first, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	second, err := scheduler.Trigger(context.Background(), "nightly")
+	if err != nil {
+		t.Fatalf("Trigger while running: %v", err)
+	}
+	if second.LastJobId == first.LastJobId {
+		t.Fatal("no new job started")
+	}
+
+	// Trigger returns once the previous job is done.
+	job, err := jobs.Get(first.LastJobId)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if job.Status != domain.JobStatusCanceled {
+		t.Errorf("previous job status = %s, want %s", job.Status, domain.JobStatusCanceled)
+	}
+
+	close(provider.release)
+	if job := waitJob(t, jobs, second.LastJobId); job.Status != domain.JobStatusCompleted {
+		t.Errorf("new job status = %s, want %s", job.Status, domain.JobStatusCompleted)
+	}
+}
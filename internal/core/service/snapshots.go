@@ -0,0 +1,93 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+// SnapshotStore keeps the last result of every product per subdivision and
+// customer, which delta runs reuse for products whose base price did not change.
+type SnapshotStore interface {
+	Get(subdivisionId, mobilePhone, productId string) (*domain.PriceSnapshot, bool)
+	Put(snapshots []*domain.PriceSnapshot) error
+}
+
+func WithSnapshotStore(store SnapshotStore) SyncOption {
+	return func(s *SyncService) {
+		s.snapshots = store
+	}
+}
+
+// RunOption changes how a single run is processed.
+type RunOption func(run *domain.SyncRun)
+
+// WithDelta only sends products that are new or whose base price changed
+// since the last run; the rest are answered from the snapshot store. Without
+// a snapshot store every product is sent.
+func WithDelta(enabled bool) RunOption {
+	return func(run *domain.SyncRun) {
+		run.Delta = enabled
+	}
+}
+
+// WithMobilePhone calculates personal prices for the customer with the given
+// mobile phone. Cached prices and snapshots are only shared between runs for
+// the same customer.
+func WithMobilePhone(mobilePhone string) RunOption {
+	return func(run *domain.SyncRun) {
+		run.MobilePhone = strings.TrimSpace(mobilePhone)
+	}
+}
+
+func (s *SyncService) lookupSnapshot(run *domain.SyncRun, product *domain.BasePrice) (*domain.ImportModelRep, bool) {
+	if !run.Delta || s.snapshots == nil {
+		return nil, false
+	}
+	snapshot, ok := s.snapshots.Get(run.SubdivisionId, run.MobilePhone, product.ProductId)
+	if !ok || !snapshot.BasePrice.Equal(product.Price) || snapshot.Rep == nil {
+		return nil, false
+	}
+	return snapshot.Rep.WithSource(domain.ItemSourceSnapshot), true
+}
+
+// saveSnapshots records reps as the latest results for products. Runs update
+// the snapshots whether or not they are delta runs, so a delta run always
+// compares against the most recent prices. Personal prices are only kept
+// for delta runs, which are the only ones to read them back.
+func (s *SyncService) saveSnapshots(run *domain.SyncRun, products []*domain.BasePrice, reps []*domain.ImportModelRep) {
+	if s.snapshots == nil || len(reps) == 0 || run.MobilePhone != "" && !run.Delta {
+		return
+	}
+	byId := repsByProduct(reps)
+	now := s.timeSource()
+	snapshots := make([]*domain.PriceSnapshot, 0, len(reps))
+	for _, product := range products {
+		rep, ok := byId[product.ProductId]
+		if !ok {
+			continue
+		}
+		snapshots = append(snapshots, &domain.PriceSnapshot{
+			SubdivisionId: run.SubdivisionId,
+			MobilePhone:   run.MobilePhone,
+			ProductId:     product.ProductId,
+			BasePrice:     product.Price,
+			Rep:           rep,
+			UpdatedAt:     now,
+		})
+	}
+	if err := s.snapshots.Put(snapshots); err != nil {
+		s.logger.Error("failed to save snapshots", slog.String("subdivision", run.SubdivisionId), slog.String("error", err.Error()))
+	}
+}
+
+func repsByProduct(reps []*domain.ImportModelRep) map[string]*domain.ImportModelRep {
+	byId := make(map[string]*domain.ImportModelRep, len(reps))
+	for _, rep := range reps {
+		if rep.FinalPrice != nil {
+			byId[rep.FinalPrice.ProductId] = rep
+		}
+	}
+	return byId
+}
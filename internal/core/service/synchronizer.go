@@ -9,6 +9,7 @@ import (
 
 	"github.com/ExonegeS/mechta-two-weeks/config"
 	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+	"github.com/ExonegeS/mechta-two-weeks/pkg/httpclient"
 )
 
 type EntityDataProvider interface {
@@ -18,16 +19,40 @@ type EntityDataProvider interface {
 }
 
 type Result struct {
-	Req  *domain.ImportModelReq
-	Data []*domain.ImportModelRep
-	Err  error
+	Req       *domain.ImportModelReq
+	Batch     *domain.SyncBatch
+	Cached    []*domain.ImportModelRep
+	Unchanged []*domain.ImportModelRep
+	Data      []*domain.ImportModelRep
+	Failed    []*domain.FailedPrice
+	Err       error
+	// Coalesced counts the products answered by another run.
+	Coalesced int
+	// Duration covers the whole batch, including bisection.
+	Duration time.Duration
 }
 
+// ProgressFunc is called once per finished batch with its outcome. Calls are
+// serialized, so implementations don't need their own locking.
+type ProgressFunc func(outcome *domain.BatchOutcome)
+
 type SyncService struct {
 	cfg           config.WorkerConfig
 	logger        *slog.Logger
 	timeSource    func() time.Time
 	entityDataAPI EntityDataProvider
+	queue         BatchQueue
+	batchSizer    *BatchSizer
+	metrics       *syncMetrics
+	cache         *PriceCache
+	snapshots     SnapshotStore
+	catalog       *PromotionCatalog
+	validator     *Validator
+	flights       flightGroup
+
+	drainMu  sync.Mutex
+	draining chan struct{}
+	inflight sync.WaitGroup
 }
 
 func NewSyncService(
@@ -35,8 +60,20 @@ func NewSyncService(
 	logger *slog.Logger,
 	timeSource func() time.Time,
 	api EntityDataProvider,
+	opts ...SyncOption,
 ) *SyncService {
-	return &SyncService{cfg, logger, timeSource, api}
+	s := &SyncService{
+		cfg:           cfg,
+		logger:        logger,
+		timeSource:    timeSource,
+		entityDataAPI: api,
+		batchSizer:    NewBatchSizer(cfg, logger, timeSource),
+		draining:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *SyncService) GetData(
@@ -44,56 +81,190 @@ func (s *SyncService) GetData(
 	subdivisionId string,
 	calculationTime time.Time,
 	products []*domain.BasePrice,
-) (processed []*domain.ImportModelRep, failed []*domain.BasePrice, err error) {
+	opts ...RunOption,
+) (*domain.SyncResult, error) {
+	return s.GetDataWithProgress(ctx, subdivisionId, calculationTime, products, nil, opts...)
+}
 
-	batchSize := int(s.cfg.BatchSize)
-	if batchSize < 1 {
-		batchSize = len(products)
+func (s *SyncService) GetDataWithProgress(
+	ctx context.Context,
+	subdivisionId string,
+	calculationTime time.Time,
+	products []*domain.BasePrice,
+	progress ProgressFunc,
+	opts ...RunOption,
+) (*domain.SyncResult, error) {
+	run, err := s.NewRun(subdivisionId, calculationTime, products, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return s.ProcessRun(ctx, run, progress)
+}
 
-	totalBatches := (len(products) + batchSize - 1) / batchSize
-	jobs := make(chan *domain.ImportModelReq, totalBatches)
-	results := make(chan Result, totalBatches)
-	var wg sync.WaitGroup
+// StreamData works like GetDataWithProgress, but results are only handed to
+// progress and never collected, so memory use doesn't grow with the catalog.
+func (s *SyncService) StreamData(
+	ctx context.Context,
+	subdivisionId string,
+	calculationTime time.Time,
+	products []*domain.BasePrice,
+	progress ProgressFunc,
+	opts ...RunOption,
+) error {
+	run, err := s.NewRun(subdivisionId, calculationTime, products, opts...)
+	if err != nil {
+		return err
+	}
+	return s.StreamRun(ctx, run, progress)
+}
+
+// StreamRun is StreamData for a run made with NewRun, for callers that
+// have to answer NewRun errors before they start streaming.
+func (s *SyncService) StreamRun(
+	ctx context.Context,
+	run *domain.SyncRun,
+	progress ProgressFunc,
+) error {
+	return s.processRun(ctx, run, progress)
+}
+
+// ProcessRun sends every product of run that has no recorded outcome yet.
+// The rejected products and the outcomes already stored on run.Batches are
+// reported first, so a resumed run returns the same result as one that was
+// never interrupted.
+func (s *SyncService) ProcessRun(
+	ctx context.Context,
+	run *domain.SyncRun,
+	progress ProgressFunc,
+) (*domain.SyncResult, error) {
+	result := &domain.SyncResult{}
+	var placeholders domain.PlaceholderGroups
+	err := s.processRun(ctx, run, func(outcome *domain.BatchOutcome) {
+		result.Add(outcome)
+		placeholders.Add(outcome.Processed)
+		if progress != nil {
+			progress(outcome)
+		}
+	})
+	result.Placeholders = placeholders.List()
+	return result, err
+}
+
+func (s *SyncService) processRun(
+	ctx context.Context,
+	run *domain.SyncRun,
+	progress ProgressFunc,
+) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	defer s.inflight.Done()
+
+	if len(run.Rejected) > 0 {
+		s.metrics.observeRejected(run.SubdivisionId, len(run.Rejected))
+		progress(&domain.BatchOutcome{Rejected: run.Rejected})
+	}
+	copies := newFanOut(run.Duplicates)
+	nextSeq := 0
+	for _, b := range run.Batches {
+		nextSeq = max(nextSeq, b.Seq+1)
+		if b.Outcome != nil {
+			progress(b.Outcome)
+		}
+	}
 
 	numWorkers := int(s.cfg.MaxWorkers)
 	if numWorkers < 1 {
 		numWorkers = 1
 	}
+	jobs := make(chan Result)
+	results := make(chan Result, numWorkers)
+	var wg sync.WaitGroup
+
+	s.metrics.addWorkers(numWorkers)
+	defer s.metrics.addWorkers(-numWorkers)
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for req := range jobs {
-				data, err := s.entityDataAPI.GetFinalPriceInfo(ctx, req)
-				if err != nil {
-					results <- Result{Req: req, Err: err}
+			for job := range jobs {
+				// Every product of the batch was cached or unchanged.
+				if len(job.Req.Products) == 0 {
+					results <- job
 					continue
 				}
-				results <- Result{Data: data, Err: nil}
+
+				s.metrics.busy(1)
+				start := s.timeSource()
+				job.Data, job.Failed, job.Coalesced, job.Err = s.fetchShared(ctx, job.Req)
+				s.cache.save(job.Req.SubdivisionId, job.Req.MobilePhone, job.Req.Products, job.Data)
+				s.saveSnapshots(run, job.Req.Products, job.Data)
+				job.Duration = s.timeSource().Sub(start)
+				s.metrics.busy(-1)
+				results <- job
 			}
 		}()
 	}
 
+	// Batches are cut right before they are handed to a worker, so every
+	// batch uses the size that reflects the latest Mindbox responses.
+	// drained is only read after results is closed, which happens after
+	// the producer returns.
+	var drained bool
 	go func() {
-		for i := 0; i < len(products); i += batchSize {
-			high := i + batchSize
-			if high > len(products) {
-				high = len(products)
-			}
-			chunk := products[i:high]
-			req := &domain.ImportModelReq{
-				SubdivisionId:   subdivisionId,
-				CalculationTime: calculationTime,
-				Products:        chunk,
-			}
-			select {
-			case jobs <- req:
-			case <-ctx.Done():
-				break
+		defer close(jobs)
+		for _, r := range pendingRanges(run) {
+			for i := r[0]; i < r[1]; {
+				// A batch covers as many products as it takes to
+				// collect a full batch of products that are neither
+				// unchanged since the last delta run nor cached; only
+				// those are sent to Mindbox.
+				batch := &domain.SyncBatch{Seq: nextSeq, Offset: i}
+				nextSeq++
+				size := s.batchSizer.Size()
+				var (
+					misses    []*domain.BasePrice
+					cached    []*domain.ImportModelRep
+					cachedFor []*domain.BasePrice
+					unchanged []*domain.ImportModelRep
+				)
+				for ; i < r[1] && len(misses) < size; i++ {
+					product := run.Products[i]
+					if rep, ok := s.lookupSnapshot(run, product); ok {
+						unchanged = append(unchanged, rep)
+						continue
+					}
+					if rep, ok := s.cache.lookup(run.SubdivisionId, run.MobilePhone, product); ok {
+						cached = append(cached, rep)
+						cachedFor = append(cachedFor, product)
+						continue
+					}
+					misses = append(misses, product)
+				}
+				batch.Count = i - batch.Offset
+				s.saveSnapshots(run, cachedFor, cached)
+
+				req := &domain.ImportModelReq{
+					SubdivisionId:   run.SubdivisionId,
+					CalculationTime: run.CalculationTime,
+					Products:        misses,
+					MobilePhone:     run.MobilePhone,
+				}
+				if s.journaled(run) {
+					if err := s.queue.Enqueue(run.Id, batch); err != nil {
+						s.logger.Error("failed to enqueue batch", slog.String("run", run.Id), slog.String("error", err.Error()))
+					}
+				}
+				select {
+				case jobs <- Result{Req: req, Batch: batch, Cached: cached, Unchanged: unchanged}:
+				case <-ctx.Done():
+					return
+				case <-s.draining:
+					drained = true
+					return
+				}
 			}
 		}
-		close(jobs)
 	}()
 
 	go func() {
@@ -102,16 +273,74 @@ func (s *SyncService) GetData(
 	}()
 
 	for res := range results {
+		outcome := &domain.BatchOutcome{
+			Processed:    s.catalog.enrich(append(append(res.Unchanged, res.Cached...), res.Data...)),
+			Failed:       res.Failed,
+			CacheHits:    len(res.Cached),
+			CacheMisses:  len(res.Req.Products) - res.Coalesced,
+			SnapshotHits: len(res.Unchanged),
+			Coalesced:    res.Coalesced,
+		}
 		if res.Err != nil {
 			s.logger.Error("GetData worker error", slog.String("err", res.Err.Error()))
-			failed = append(failed, res.Req.Products...)
-			continue
+			outcome.Error = res.Err.Error()
+		} else {
+			s.logger.Info("GetData worker success",
+				slog.Int("processed size:", len(res.Data)),
+				slog.Int("failed size:", len(res.Failed)))
+		}
+
+		copies.apply(outcome)
+		s.metrics.observeBatch(run.SubdivisionId, outcome, res.Duration.Seconds())
+		progress(outcome)
+
+		// A batch cut short by cancellation is left without an outcome,
+		// so it is sent again when the run is resumed.
+		if s.journaled(run) && ctx.Err() == nil {
+			res.Batch.Outcome = outcome
+			if err := s.queue.Complete(run.Id, res.Batch); err != nil {
+				s.logger.Error("failed to complete batch", slog.String("run", run.Id), slog.String("error", err.Error()))
+			}
 		}
-		s.logger.Info("GetData worker success", slog.Int("processed size:", len(res.Data)))
-		processed = append(processed, res.Data...)
 	}
 
-	return processed, failed, nil
+	if drained {
+		return ErrDraining
+	}
+	return nil
+}
+
+// fetch sends req to Mindbox, bisecting it on failure if enabled. err is
+// set if the whole batch failed without bisection; its products are in
+// failed either way.
+func (s *SyncService) fetch(
+	ctx context.Context,
+	req *domain.ImportModelReq,
+) (data []*domain.ImportModelRep, failed []*domain.FailedPrice, err error) {
+	trace := &httpclient.Trace{}
+	start := s.timeSource()
+	data, err = s.entityDataAPI.GetFinalPriceInfo(httpclient.WithTrace(ctx, trace), req)
+	latency := s.timeSource().Sub(start)
+	if trace.Requests() > 0 {
+		latency = trace.Elapsed()
+	}
+	s.batchSizer.Observe(ctx, latency, err)
+	switch {
+	case err != nil && s.cfg.BisectEnabled:
+		s.logger.Error("GetData worker error", slog.String("err", err.Error()))
+		data, failed = s.bisect(ctx, req, err)
+		err = nil
+	case err != nil:
+		data, failed = nil, newFailedPrices(req.Products, err)
+	}
+	for _, rep := range data {
+		rep.Source = domain.ItemSourceFresh
+	}
+	return data, failed, err
+}
+
+func (s *SyncService) BatchSizeStats() domain.BatchSizeStats {
+	return s.batchSizer.Stats()
 }
 
 func (s *SyncService) GetPromotionsInfo(
@@ -119,3 +348,20 @@ func (s *SyncService) GetPromotionsInfo(
 ) ([]*domain.ImportPromotionsRep, error) {
 	return s.entityDataAPI.GetPromotionsInfo(ctx)
 }
+
+// RefreshPromotions reads the promotions export and passes it to the price
+// cache and the promotion catalog, so a change is picked up without waiting
+// for their own refresh.
+func (s *SyncService) RefreshPromotions(
+	ctx context.Context,
+) ([]*domain.ImportPromotionsRep, error) {
+	promotions, err := s.entityDataAPI.GetPromotionsInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.catalog.update(promotions)
+	if err := s.cache.update(promotions); err != nil {
+		s.logger.Error("failed to refresh price cache", slog.String("error", err.Error()))
+	}
+	return promotions, nil
+}
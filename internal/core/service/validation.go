@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ExonegeS/mechta-two-weeks/config"
+	"github.com/ExonegeS/mechta-two-weeks/internal/core/domain"
+)
+
+var ErrTooManyItems = errors.New("too many items")
+
+// DedupPolicy decides which occurrence of a product ID listed more than once
+// with different prices is kept. Occurrences with the same price are all
+// kept, as the run prices them only once.
+type DedupPolicy string
+
+const (
+	DedupFirst DedupPolicy = "first"
+	DedupLast  DedupPolicy = "last"
+	// DedupError rejects every occurrence of a conflicting ID.
+	DedupError DedupPolicy = "error"
+)
+
+// Validator checks the products of a run before anything is sent to
+// Mindbox. Invalid products are rejected one by one; only a list longer
+// than MaxItems fails as a whole.
+type Validator struct {
+	maxItems  int
+	idPattern *regexp.Regexp
+	minPrice  *domain.Money
+	maxPrice  *domain.Money
+	dedup     DedupPolicy
+}
+
+func NewValidator(cfg config.Validation) (*Validator, error) {
+	v := &Validator{
+		maxItems: int(cfg.MaxItems),
+		dedup:    DedupPolicy(cfg.Dedup),
+	}
+	switch v.dedup {
+	case "":
+		v.dedup = DedupFirst
+	case DedupFirst, DedupLast, DedupError:
+	default:
+		return nil, fmt.Errorf("unknown dedup policy %q", cfg.Dedup)
+	}
+	if cfg.IdPattern != "" {
+		pattern, err := regexp.Compile(cfg.IdPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id pattern: %w", err)
+		}
+		v.idPattern = pattern
+	}
+	for _, bound := range []struct {
+		value string
+		dst   **domain.Money
+	}{
+		{cfg.MinPrice, &v.minPrice},
+		{cfg.MaxPrice, &v.maxPrice},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		price, err := domain.ParseMoney(bound.value, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid price bound: %w", err)
+		}
+		*bound.dst = &price
+	}
+	if v.minPrice != nil && v.maxPrice != nil && v.minPrice.Cmp(*v.maxPrice) > 0 {
+		return nil, fmt.Errorf("min price %s is above max price %s", v.minPrice, v.maxPrice)
+	}
+	return v, nil
+}
+
+func WithValidator(validator *Validator) SyncOption {
+	return func(s *SyncService) {
+		s.validator = validator
+	}
+}
+
+// WithRejected adds products the caller already rejected, for instance
+// because their price could not be parsed, to the ones validation rejects.
+func WithRejected(rejected ...*domain.RejectedPrice) RunOption {
+	return func(run *domain.SyncRun) {
+		run.Rejected = append(run.Rejected, rejected...)
+	}
+}
+
+// Validate splits products into the valid ones, in their original order,
+// and the rejected ones. It fails with ErrTooManyItems if there are more
+// than MaxItems products. A nil Validator accepts everything.
+func (v *Validator) Validate(products []*domain.BasePrice) ([]*domain.BasePrice, []*domain.RejectedPrice, error) {
+	if v == nil {
+		return products, nil, nil
+	}
+	if v.maxItems > 0 && len(products) > v.maxItems {
+		return nil, nil, fmt.Errorf("%w: %d, at most %d are allowed", ErrTooManyItems, len(products), v.maxItems)
+	}
+
+	var rejected []*domain.RejectedPrice
+	reject := func(product *domain.BasePrice, reason domain.RejectReason, msg string) {
+		rejected = append(rejected, &domain.RejectedPrice{
+			BasePrice: product,
+			Reason:    reason,
+			Error:     msg,
+		})
+	}
+
+	candidates := make([]*domain.BasePrice, 0, len(products))
+	for _, product := range products {
+		if reason, msg := v.check(product); msg != "" {
+			reject(product, reason, msg)
+			continue
+		}
+		candidates = append(candidates, product)
+	}
+
+	// An ID listed more than once only conflicts if its prices differ;
+	// exact copies are coalesced by the run. kept holds the occurrence of
+	// every conflicting ID that survives, or nil if none does.
+	first := make(map[string]*domain.BasePrice, len(candidates))
+	kept := make(map[string]*domain.BasePrice)
+	for _, product := range candidates {
+		prev, seen := first[product.ProductId]
+		if !seen {
+			first[product.ProductId] = product
+			continue
+		}
+		if _, conflict := kept[product.ProductId]; !conflict && !prev.Price.Equal(product.Price) {
+			kept[product.ProductId] = prev
+		}
+	}
+	for _, product := range candidates {
+		if _, conflict := kept[product.ProductId]; !conflict {
+			continue
+		}
+		switch v.dedup {
+		case DedupLast:
+			kept[product.ProductId] = product
+		case DedupError:
+			kept[product.ProductId] = nil
+		}
+	}
+
+	valid := make([]*domain.BasePrice, 0, len(candidates))
+	for _, product := range candidates {
+		survivor, conflict := kept[product.ProductId]
+		switch {
+		case !conflict, survivor != nil && survivor.Price.Equal(product.Price):
+			valid = append(valid, product)
+		case survivor == nil:
+			reject(product, domain.RejectDuplicate, "product is listed more than once with different prices")
+		default:
+			reject(product, domain.RejectDuplicate,
+				fmt.Sprintf("product is listed more than once with different prices, price %s is used", survivor.Price))
+		}
+	}
+	return valid, rejected, nil
+}
+
+// check returns why product is invalid, or an empty message if it is not.
+func (v *Validator) check(product *domain.BasePrice) (domain.RejectReason, string) {
+	switch {
+	case strings.TrimSpace(product.ProductId) == "":
+		return domain.RejectEmptyId, "product id is empty"
+	case product.Price.Currency() != domain.DefaultCurrency:
+		// Mindbox only takes plain amounts in the default currency.
+		return domain.RejectCurrency, fmt.Sprintf("currency %s is not supported, prices must be in %s",
+			product.Price.Currency(), domain.DefaultCurrency)
+	case v.idPattern != nil && !v.idPattern.MatchString(product.ProductId):
+		return domain.RejectInvalidId, fmt.Sprintf("product id %q does not match %s", product.ProductId, v.idPattern)
+	case v.minPrice != nil && product.Price.Cmp(*v.minPrice) < 0:
+		return domain.RejectPriceRange, fmt.Sprintf("price %s is below %s", product.Price, v.minPrice)
+	case v.maxPrice != nil && product.Price.Cmp(*v.maxPrice) > 0:
+		return domain.RejectPriceRange, fmt.Sprintf("price %s is above %s", product.Price, v.maxPrice)
+	}
+	return "", ""
+}
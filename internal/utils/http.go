@@ -9,6 +9,8 @@ import (
 	"time"
 )
 
+const ContentTypeNDJSON = "application/x-ndjson"
+
 func ParseJSON(r *http.Request, v any) error {
 	if r.Body == nil {
 		return fmt.Errorf("missing request body")
@@ -22,6 +24,20 @@ func WriteJSON(w http.ResponseWriter, status int, v any) error {
 	return json.NewEncoder(w).Encode(v)
 }
 
+// AcceptsNDJSON reports whether the client asked for a newline-delimited
+// JSON response.
+func AcceptsNDJSON(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(v, ",") {
+			mediaType, _, _ := strings.Cut(part, ";")
+			if strings.TrimSpace(mediaType) == ContentTypeNDJSON {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func WriteMessage(w http.ResponseWriter, status int, msg string) {
 	WriteJSON(w, status, map[string]string{"message": msg})
 }
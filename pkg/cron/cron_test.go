@@ -0,0 +1,203 @@
+package cron
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseErrors(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@every 500ms",
+		"@every soon",
+		"@fortnightly",
+	}
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	local := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, newYork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+			from: utc("2024-06-03 10:07").Add(30 * time.Second),
+			want: []time.Time{utc("2024-06-03 10:08"), utc("2024-06-03 10:09")},
+		},
+		{
+			name: "step",
+			spec: "*/15 * * * *",
+			from: utc("2024-06-03 10:07"),
+			want: []time.Time{utc("2024-06-03 10:15"), utc("2024-06-03 10:30"), utc("2024-06-03 10:45"), utc("2024-06-03 11:00")},
+		},
+		{
+			name: "step from a value",
+			spec: "5/20 * * * *",
+			from: utc("2024-06-03 10:50"),
+			want: []time.Time{utc("2024-06-03 11:05"), utc("2024-06-03 11:25")},
+		},
+		{
+			name: "range with step",
+			spec: "5-10/2 * * * *",
+			from: utc("2024-06-03 10:06"),
+			want: []time.Time{utc("2024-06-03 10:07"), utc("2024-06-03 10:09"), utc("2024-06-03 11:05")},
+		},
+		{
+			name: "list and hour range",
+			spec: "0,30 9-10 * * *",
+			from: utc("2024-06-03 10:30"),
+			want: []time.Time{utc("2024-06-04 09:00"), utc("2024-06-04 09:30"), utc("2024-06-04 10:00")},
+		},
+		{
+			name: "weekdays",
+			spec: "30 9 * * 1-5",
+			from: utc("2024-06-07 10:00"),
+			want: []time.Time{utc("2024-06-10 09:30"), utc("2024-06-11 09:30")},
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 * * 7",
+			from: utc("2024-06-03 00:00"),
+			want: []time.Time{utc("2024-06-09 00:00"), utc("2024-06-16 00:00")},
+		},
+		{
+			name: "day of month or day of week",
+			spec: "0 0 13 * 5",
+			from: utc("2024-09-07 00:00"),
+			want: []time.Time{utc("2024-09-13 00:00"), utc("2024-09-20 00:00"), utc("2024-09-27 00:00"), utc("2024-10-04 00:00"), utc("2024-10-11 00:00"), utc("2024-10-13 00:00")},
+		},
+		{
+			name: "day of month step and day of week",
+			spec: "0 0 */2 * 1",
+			from: utc("2024-06-04 00:00"),
+			want: []time.Time{utc("2024-06-17 00:00"), utc("2024-07-01 00:00"), utc("2024-07-15 00:00")},
+		},
+		{
+			name: "day of month with any day of week",
+			spec: "0 0 31 * ?",
+			from: utc("2024-04-01 00:00"),
+			want: []time.Time{utc("2024-05-31 00:00"), utc("2024-07-31 00:00")},
+		},
+		{
+			name: "month",
+			spec: "0 0 1 1,7 *",
+			from: utc("2024-01-01 00:00"),
+			want: []time.Time{utc("2024-07-01 00:00"), utc("2025-01-01 00:00")},
+		},
+		{
+			name: "february 29",
+			spec: "0 12 29 2 *",
+			from: utc("2024-03-01 00:00"),
+			want: []time.Time{utc("2028-02-29 12:00"), utc("2032-02-29 12:00")},
+		},
+		{
+			name: "february 30 never fires",
+			spec: "0 0 30 2 *",
+			from: utc("2024-01-01 00:00"),
+			want: []time.Time{{}},
+		},
+		{
+			name: "hourly",
+			spec: "@hourly",
+			from: utc("2024-06-03 10:00"),
+			want: []time.Time{utc("2024-06-03 11:00"), utc("2024-06-03 12:00")},
+		},
+		{
+			name: "weekly",
+			spec: "@weekly",
+			from: utc("2024-06-03 10:00"),
+			want: []time.Time{utc("2024-06-09 00:00")},
+		},
+		{
+			name: "every",
+			spec: "@every 90s",
+			from: utc("2024-06-03 10:00").Add(500 * time.Millisecond),
+			want: []time.Time{utc("2024-06-03 10:01").Add(30 * time.Second), utc("2024-06-03 10:03")},
+		},
+		{
+			name: "time skipped by dst",
+			spec: "30 2 * * *",
+			from: local("2024-03-09 12:00"),
+			want: []time.Time{local("2024-03-09 02:30").AddDate(0, 0, 2)},
+		},
+		{
+			name: "steps across skipped hour",
+			spec: "*/30 * * * *",
+			from: local("2024-03-10 01:15"),
+			want: []time.Time{local("2024-03-10 01:30"), local("2024-03-10 03:00")},
+		},
+		{
+			name: "time repeated by dst fires once",
+			spec: "30 1 * * *",
+			from: local("2024-11-02 12:00"),
+			want: []time.Time{local("2024-11-03 01:30"), local("2024-11-04 01:30")},
+		},
+		{
+			name: "hourly through repeated hour",
+			spec: "0 * * * *",
+			from: local("2024-11-03 00:30"),
+			want: []time.Time{
+				local("2024-11-03 01:00"),
+				local("2024-11-03 01:00").Add(time.Hour),
+				local("2024-11-03 01:00").Add(2 * time.Hour),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			from := tt.from
+			for i, want := range tt.want {
+				got := schedule.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next #%d after %s = %s, want %s", i+1, from, got, want)
+				}
+				from = got
+			}
+		})
+	}
+}
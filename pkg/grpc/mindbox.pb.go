@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.5
+// 	protoc-gen-go v1.36.6
 // 	protoc        v5.29.3
 // source: mindbox.proto
 
@@ -22,17 +22,81 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type Item struct {
+// Money is an exact amount, units + nanos / 1e9, like google.type.Money.
+// nanos has the same sign as units. An empty currency_code means KZT.
+type Money struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	CurrencyCode  string                 `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	Units         int64                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	Nanos         int32                  `protobuf:"varint,3,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_mindbox_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *Money) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Money) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
+type Item struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Kept for older clients; price_money wins when both are set.
+	Price         float64 `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	PriceMoney    *Money  `protobuf:"bytes,3,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Item) Reset() {
 	*x = Item{}
-	mi := &file_mindbox_proto_msgTypes[0]
+	mi := &file_mindbox_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -44,7 +108,7 @@ func (x *Item) String() string {
 func (*Item) ProtoMessage() {}
 
 func (x *Item) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[0]
+	mi := &file_mindbox_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,7 +121,7 @@ func (x *Item) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Item.ProtoReflect.Descriptor instead.
 func (*Item) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{0}
+	return file_mindbox_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *Item) GetProductId() string {
@@ -74,22 +138,240 @@ func (x *Item) GetPrice() float64 {
 	return 0
 }
 
-type Promo struct {
+func (x *Item) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+type FailedItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=Id,proto3" json:"Id,omitempty"`
-	ExternalId    string                 `protobuf:"bytes,2,opt,name=ExternalId,proto3" json:"ExternalId,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=Type,proto3" json:"Type,omitempty"`
-	Name          string                 `protobuf:"bytes,4,opt,name=Name,proto3" json:"Name,omitempty"`
-	SchemaId      string                 `protobuf:"bytes,5,opt,name=SchemaId,proto3" json:"SchemaId,omitempty"`
-	StartDate     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=StartDate,proto3" json:"StartDate,omitempty"`
-	EndDate       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=EndDate,proto3" json:"EndDate,omitempty"`
+	Item          *Item                  `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	HttpStatus    int32                  `protobuf:"varint,3,opt,name=http_status,json=httpStatus,proto3" json:"http_status,omitempty"`
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	MindboxStatus string                 `protobuf:"bytes,5,opt,name=mindbox_status,json=mindboxStatus,proto3" json:"mindbox_status,omitempty"`
+	Retryable     bool                   `protobuf:"varint,6,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailedItem) Reset() {
+	*x = FailedItem{}
+	mi := &file_mindbox_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailedItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedItem) ProtoMessage() {}
+
+func (x *FailedItem) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedItem.ProtoReflect.Descriptor instead.
+func (*FailedItem) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FailedItem) GetItem() *Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *FailedItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FailedItem) GetHttpStatus() int32 {
+	if x != nil {
+		return x.HttpStatus
+	}
+	return 0
+}
+
+func (x *FailedItem) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *FailedItem) GetMindboxStatus() string {
+	if x != nil {
+		return x.MindboxStatus
+	}
+	return ""
+}
+
+func (x *FailedItem) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *FailedItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// RejectedItem is an item that failed validation and was never sent to
+// Mindbox.
+type RejectedItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          *Item                  `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedItem) Reset() {
+	*x = RejectedItem{}
+	mi := &file_mindbox_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedItem) ProtoMessage() {}
+
+func (x *RejectedItem) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedItem.ProtoReflect.Descriptor instead.
+func (*RejectedItem) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RejectedItem) GetItem() *Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *RejectedItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RejectedItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BalanceType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SystemName    string                 `protobuf:"bytes,1,opt,name=SystemName,proto3" json:"SystemName,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceType) Reset() {
+	*x = BalanceType{}
+	mi := &file_mindbox_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceType) ProtoMessage() {}
+
+func (x *BalanceType) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceType.ProtoReflect.Descriptor instead.
+func (*BalanceType) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BalanceType) GetSystemName() string {
+	if x != nil {
+		return x.SystemName
+	}
+	return ""
+}
+
+func (x *BalanceType) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Promo struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int32                  `protobuf:"varint,1,opt,name=Id,proto3" json:"Id,omitempty"`
+	ExternalId string                 `protobuf:"bytes,2,opt,name=ExternalId,proto3" json:"ExternalId,omitempty"`
+	Type       string                 `protobuf:"bytes,3,opt,name=Type,proto3" json:"Type,omitempty"`
+	Name       string                 `protobuf:"bytes,4,opt,name=Name,proto3" json:"Name,omitempty"`
+	SchemaId   string                 `protobuf:"bytes,5,opt,name=SchemaId,proto3" json:"SchemaId,omitempty"`
+	StartDate  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=StartDate,proto3" json:"StartDate,omitempty"`
+	EndDate    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=EndDate,proto3" json:"EndDate,omitempty"`
+	// Discount per item, or the balance accrued if BalanceType is set.
+	Amount        float64      `protobuf:"fixed64,8,opt,name=Amount,proto3" json:"Amount,omitempty"`
+	GroupingKey   string       `protobuf:"bytes,9,opt,name=GroupingKey,proto3" json:"GroupingKey,omitempty"`
+	BalanceType   *BalanceType `protobuf:"bytes,10,opt,name=BalanceType,proto3" json:"BalanceType,omitempty"`
+	AmountMoney   *Money       `protobuf:"bytes,11,opt,name=AmountMoney,proto3" json:"AmountMoney,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Promo) Reset() {
 	*x = Promo{}
-	mi := &file_mindbox_proto_msgTypes[1]
+	mi := &file_mindbox_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -101,7 +383,7 @@ func (x *Promo) String() string {
 func (*Promo) ProtoMessage() {}
 
 func (x *Promo) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[1]
+	mi := &file_mindbox_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -114,7 +396,7 @@ func (x *Promo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Promo.ProtoReflect.Descriptor instead.
 func (*Promo) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{1}
+	return file_mindbox_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Promo) GetId() int32 {
@@ -166,6 +448,97 @@ func (x *Promo) GetEndDate() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Promo) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Promo) GetGroupingKey() string {
+	if x != nil {
+		return x.GroupingKey
+	}
+	return ""
+}
+
+func (x *Promo) GetBalanceType() *BalanceType {
+	if x != nil {
+		return x.BalanceType
+	}
+	return nil
+}
+
+func (x *Promo) GetAmountMoney() *Money {
+	if x != nil {
+		return x.AmountMoney
+	}
+	return nil
+}
+
+// BalanceAccrual sums the amounts of every promotion accruing to a balance.
+type BalanceAccrual struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BalanceType   *BalanceType           `protobuf:"bytes,1,opt,name=BalanceType,proto3" json:"BalanceType,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=Amount,proto3" json:"Amount,omitempty"`
+	AmountMoney   *Money                 `protobuf:"bytes,3,opt,name=AmountMoney,proto3" json:"AmountMoney,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceAccrual) Reset() {
+	*x = BalanceAccrual{}
+	mi := &file_mindbox_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceAccrual) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceAccrual) ProtoMessage() {}
+
+func (x *BalanceAccrual) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceAccrual.ProtoReflect.Descriptor instead.
+func (*BalanceAccrual) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BalanceAccrual) GetBalanceType() *BalanceType {
+	if x != nil {
+		return x.BalanceType
+	}
+	return nil
+}
+
+func (x *BalanceAccrual) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *BalanceAccrual) GetAmountMoney() *Money {
+	if x != nil {
+		return x.AmountMoney
+	}
+	return nil
+}
+
+// PromoPlaceholder on an ImportModel only lists its own product in
+// ProductIds; the placeholders of a response list all products showing them.
 type PromoPlaceholder struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PhId          string                 `protobuf:"bytes,1,opt,name=PhId,proto3" json:"PhId,omitempty"`
@@ -180,7 +553,7 @@ type PromoPlaceholder struct {
 
 func (x *PromoPlaceholder) Reset() {
 	*x = PromoPlaceholder{}
-	mi := &file_mindbox_proto_msgTypes[2]
+	mi := &file_mindbox_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -192,7 +565,7 @@ func (x *PromoPlaceholder) String() string {
 func (*PromoPlaceholder) ProtoMessage() {}
 
 func (x *PromoPlaceholder) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[2]
+	mi := &file_mindbox_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -205,7 +578,7 @@ func (x *PromoPlaceholder) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PromoPlaceholder.ProtoReflect.Descriptor instead.
 func (*PromoPlaceholder) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{2}
+	return file_mindbox_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PromoPlaceholder) GetPhId() string {
@@ -255,13 +628,22 @@ type ImportModel struct {
 	FinalPrice       *Item                  `protobuf:"bytes,1,opt,name=FinalPrice,proto3" json:"FinalPrice,omitempty"`
 	Promotions       []*Promo               `protobuf:"bytes,2,rep,name=Promotions,proto3" json:"Promotions,omitempty"`
 	PromoPlaceholder []*PromoPlaceholder    `protobuf:"bytes,3,rep,name=PromoPlaceholder,proto3" json:"PromoPlaceholder,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// fresh, cache or snapshot.
+	Source    string  `protobuf:"bytes,4,opt,name=Source,proto3" json:"Source,omitempty"`
+	BasePrice float64 `protobuf:"fixed64,5,opt,name=BasePrice,proto3" json:"BasePrice,omitempty"`
+	// BasePrice minus the final price.
+	Discount float64           `protobuf:"fixed64,6,opt,name=Discount,proto3" json:"Discount,omitempty"`
+	Accruals []*BalanceAccrual `protobuf:"bytes,7,rep,name=Accruals,proto3" json:"Accruals,omitempty"`
+	// Exact BasePrice and Discount; the double fields are rounded.
+	BasePriceMoney *Money `protobuf:"bytes,8,opt,name=BasePriceMoney,proto3" json:"BasePriceMoney,omitempty"`
+	DiscountMoney  *Money `protobuf:"bytes,9,opt,name=DiscountMoney,proto3" json:"DiscountMoney,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ImportModel) Reset() {
 	*x = ImportModel{}
-	mi := &file_mindbox_proto_msgTypes[3]
+	mi := &file_mindbox_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -273,7 +655,7 @@ func (x *ImportModel) String() string {
 func (*ImportModel) ProtoMessage() {}
 
 func (x *ImportModel) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[3]
+	mi := &file_mindbox_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -286,7 +668,7 @@ func (x *ImportModel) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ImportModel.ProtoReflect.Descriptor instead.
 func (*ImportModel) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{3}
+	return file_mindbox_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ImportModel) GetFinalPrice() *Item {
@@ -310,18 +692,66 @@ func (x *ImportModel) GetPromoPlaceholder() []*PromoPlaceholder {
 	return nil
 }
 
+func (x *ImportModel) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ImportModel) GetBasePrice() float64 {
+	if x != nil {
+		return x.BasePrice
+	}
+	return 0
+}
+
+func (x *ImportModel) GetDiscount() float64 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *ImportModel) GetAccruals() []*BalanceAccrual {
+	if x != nil {
+		return x.Accruals
+	}
+	return nil
+}
+
+func (x *ImportModel) GetBasePriceMoney() *Money {
+	if x != nil {
+		return x.BasePriceMoney
+	}
+	return nil
+}
+
+func (x *ImportModel) GetDiscountMoney() *Money {
+	if x != nil {
+		return x.DiscountMoney
+	}
+	return nil
+}
+
 // Request/Response messages
 type GetFinalPriceInfoRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Items         []*Item                `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Items []*Item                `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	// Only send items that are new or whose price changed since the last run.
+	// For uploads, the flag of the first chunk is used.
+	Delta bool `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	// Calculate personal prices for the customer with this mobile phone.
+	// For uploads, the phone of the first chunk is used.
+	MobilePhone   string `protobuf:"bytes,4,opt,name=mobile_phone,json=mobilePhone,proto3" json:"mobile_phone,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetFinalPriceInfoRequest) Reset() {
 	*x = GetFinalPriceInfoRequest{}
-	mi := &file_mindbox_proto_msgTypes[4]
+	mi := &file_mindbox_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -333,7 +763,7 @@ func (x *GetFinalPriceInfoRequest) String() string {
 func (*GetFinalPriceInfoRequest) ProtoMessage() {}
 
 func (x *GetFinalPriceInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[4]
+	mi := &file_mindbox_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +776,7 @@ func (x *GetFinalPriceInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetFinalPriceInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetFinalPriceInfoRequest) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{4}
+	return file_mindbox_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetFinalPriceInfoRequest) GetId() string {
@@ -363,6 +793,20 @@ func (x *GetFinalPriceInfoRequest) GetItems() []*Item {
 	return nil
 }
 
+func (x *GetFinalPriceInfoRequest) GetDelta() bool {
+	if x != nil {
+		return x.Delta
+	}
+	return false
+}
+
+func (x *GetFinalPriceInfoRequest) GetMobilePhone() string {
+	if x != nil {
+		return x.MobilePhone
+	}
+	return ""
+}
+
 type GetFinalPriceInfoResponse struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -371,13 +815,24 @@ type GetFinalPriceInfoResponse struct {
 	ProcessDuration string                 `protobuf:"bytes,4,opt,name=process_duration,json=processDuration,proto3" json:"process_duration,omitempty"`
 	Processed       []*ImportModel         `protobuf:"bytes,5,rep,name=processed,proto3" json:"processed,omitempty"`
 	Failed          []*Item                `protobuf:"bytes,6,rep,name=failed,proto3" json:"failed,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	FailedItems     []*FailedItem          `protobuf:"bytes,7,rep,name=failed_items,json=failedItems,proto3" json:"failed_items,omitempty"`
+	CacheHits       int32                  `protobuf:"varint,8,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`
+	CacheMisses     int32                  `protobuf:"varint,9,opt,name=cache_misses,json=cacheMisses,proto3" json:"cache_misses,omitempty"`
+	SnapshotHits    int32                  `protobuf:"varint,10,opt,name=snapshot_hits,json=snapshotHits,proto3" json:"snapshot_hits,omitempty"`
+	TotalRejected   int32                  `protobuf:"varint,11,opt,name=total_rejected,json=totalRejected,proto3" json:"total_rejected,omitempty"`
+	Rejected        []*RejectedItem        `protobuf:"bytes,12,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	// Items answered by a repeated item or by a concurrent request instead of
+	// their own Mindbox query.
+	Coalesced int32 `protobuf:"varint,13,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
+	// Every placeholder of processed once, with all products showing it.
+	Placeholders  []*PromoPlaceholder `protobuf:"bytes,14,rep,name=placeholders,proto3" json:"placeholders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetFinalPriceInfoResponse) Reset() {
 	*x = GetFinalPriceInfoResponse{}
-	mi := &file_mindbox_proto_msgTypes[5]
+	mi := &file_mindbox_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -389,7 +844,7 @@ func (x *GetFinalPriceInfoResponse) String() string {
 func (*GetFinalPriceInfoResponse) ProtoMessage() {}
 
 func (x *GetFinalPriceInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[5]
+	mi := &file_mindbox_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,7 +857,7 @@ func (x *GetFinalPriceInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetFinalPriceInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetFinalPriceInfoResponse) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{5}
+	return file_mindbox_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetFinalPriceInfoResponse) GetId() string {
@@ -447,6 +902,246 @@ func (x *GetFinalPriceInfoResponse) GetFailed() []*Item {
 	return nil
 }
 
+func (x *GetFinalPriceInfoResponse) GetFailedItems() []*FailedItem {
+	if x != nil {
+		return x.FailedItems
+	}
+	return nil
+}
+
+func (x *GetFinalPriceInfoResponse) GetCacheHits() int32 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *GetFinalPriceInfoResponse) GetCacheMisses() int32 {
+	if x != nil {
+		return x.CacheMisses
+	}
+	return 0
+}
+
+func (x *GetFinalPriceInfoResponse) GetSnapshotHits() int32 {
+	if x != nil {
+		return x.SnapshotHits
+	}
+	return 0
+}
+
+func (x *GetFinalPriceInfoResponse) GetTotalRejected() int32 {
+	if x != nil {
+		return x.TotalRejected
+	}
+	return 0
+}
+
+func (x *GetFinalPriceInfoResponse) GetRejected() []*RejectedItem {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+func (x *GetFinalPriceInfoResponse) GetCoalesced() int32 {
+	if x != nil {
+		return x.Coalesced
+	}
+	return 0
+}
+
+func (x *GetFinalPriceInfoResponse) GetPlaceholders() []*PromoPlaceholder {
+	if x != nil {
+		return x.Placeholders
+	}
+	return nil
+}
+
+type FinalPriceInfoSummary struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TotalProcessed  int32                  `protobuf:"varint,1,opt,name=total_processed,json=totalProcessed,proto3" json:"total_processed,omitempty"`
+	TotalFailed     int32                  `protobuf:"varint,2,opt,name=total_failed,json=totalFailed,proto3" json:"total_failed,omitempty"`
+	ProcessDuration string                 `protobuf:"bytes,3,opt,name=process_duration,json=processDuration,proto3" json:"process_duration,omitempty"`
+	CacheHits       int32                  `protobuf:"varint,4,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`
+	CacheMisses     int32                  `protobuf:"varint,5,opt,name=cache_misses,json=cacheMisses,proto3" json:"cache_misses,omitempty"`
+	SnapshotHits    int32                  `protobuf:"varint,6,opt,name=snapshot_hits,json=snapshotHits,proto3" json:"snapshot_hits,omitempty"`
+	TotalRejected   int32                  `protobuf:"varint,7,opt,name=total_rejected,json=totalRejected,proto3" json:"total_rejected,omitempty"`
+	Coalesced       int32                  `protobuf:"varint,8,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
+	Placeholders    []*PromoPlaceholder    `protobuf:"bytes,9,rep,name=placeholders,proto3" json:"placeholders,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FinalPriceInfoSummary) Reset() {
+	*x = FinalPriceInfoSummary{}
+	mi := &file_mindbox_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalPriceInfoSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalPriceInfoSummary) ProtoMessage() {}
+
+func (x *FinalPriceInfoSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalPriceInfoSummary.ProtoReflect.Descriptor instead.
+func (*FinalPriceInfoSummary) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FinalPriceInfoSummary) GetTotalProcessed() int32 {
+	if x != nil {
+		return x.TotalProcessed
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetTotalFailed() int32 {
+	if x != nil {
+		return x.TotalFailed
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetProcessDuration() string {
+	if x != nil {
+		return x.ProcessDuration
+	}
+	return ""
+}
+
+func (x *FinalPriceInfoSummary) GetCacheHits() int32 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetCacheMisses() int32 {
+	if x != nil {
+		return x.CacheMisses
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetSnapshotHits() int32 {
+	if x != nil {
+		return x.SnapshotHits
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetTotalRejected() int32 {
+	if x != nil {
+		return x.TotalRejected
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetCoalesced() int32 {
+	if x != nil {
+		return x.Coalesced
+	}
+	return 0
+}
+
+func (x *FinalPriceInfoSummary) GetPlaceholders() []*PromoPlaceholder {
+	if x != nil {
+		return x.Placeholders
+	}
+	return nil
+}
+
+type FinalPriceInfoChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Processed     []*ImportModel         `protobuf:"bytes,2,rep,name=processed,proto3" json:"processed,omitempty"`
+	FailedItems   []*FailedItem          `protobuf:"bytes,3,rep,name=failed_items,json=failedItems,proto3" json:"failed_items,omitempty"`
+	Summary       *FinalPriceInfoSummary `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	Rejected      []*RejectedItem        `protobuf:"bytes,5,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalPriceInfoChunk) Reset() {
+	*x = FinalPriceInfoChunk{}
+	mi := &file_mindbox_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalPriceInfoChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalPriceInfoChunk) ProtoMessage() {}
+
+func (x *FinalPriceInfoChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_mindbox_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalPriceInfoChunk.ProtoReflect.Descriptor instead.
+func (*FinalPriceInfoChunk) Descriptor() ([]byte, []int) {
+	return file_mindbox_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FinalPriceInfoChunk) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FinalPriceInfoChunk) GetProcessed() []*ImportModel {
+	if x != nil {
+		return x.Processed
+	}
+	return nil
+}
+
+func (x *FinalPriceInfoChunk) GetFailedItems() []*FailedItem {
+	if x != nil {
+		return x.FailedItems
+	}
+	return nil
+}
+
+func (x *FinalPriceInfoChunk) GetSummary() *FinalPriceInfoSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *FinalPriceInfoChunk) GetRejected() []*RejectedItem {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
 type GetPromoInfoResponse struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	TotalPromotions int32                  `protobuf:"varint,1,opt,name=total_promotions,json=totalPromotions,proto3" json:"total_promotions,omitempty"`
@@ -458,7 +1153,7 @@ type GetPromoInfoResponse struct {
 
 func (x *GetPromoInfoResponse) Reset() {
 	*x = GetPromoInfoResponse{}
-	mi := &file_mindbox_proto_msgTypes[6]
+	mi := &file_mindbox_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +1165,7 @@ func (x *GetPromoInfoResponse) String() string {
 func (*GetPromoInfoResponse) ProtoMessage() {}
 
 func (x *GetPromoInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[6]
+	mi := &file_mindbox_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +1178,7 @@ func (x *GetPromoInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPromoInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetPromoInfoResponse) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{6}
+	return file_mindbox_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *GetPromoInfoResponse) GetTotalPromotions() int32 {
@@ -515,7 +1210,7 @@ type Empty struct {
 
 func (x *Empty) Reset() {
 	*x = Empty{}
-	mi := &file_mindbox_proto_msgTypes[7]
+	mi := &file_mindbox_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -527,7 +1222,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_mindbox_proto_msgTypes[7]
+	mi := &file_mindbox_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -540,104 +1235,137 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_mindbox_proto_rawDescGZIP(), []int{7}
+	return file_mindbox_proto_rawDescGZIP(), []int{14}
 }
 
 var File_mindbox_proto protoreflect.FileDescriptor
 
-var file_mindbox_proto_rawDesc = string([]byte{
-	0x0a, 0x0d, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x07, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3b, 0x0a, 0x04, 0x49, 0x74, 0x65,
-	0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x49, 0x64,
-	0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
-	0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x22, 0xeb, 0x01, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6d, 0x6f,
-	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x49, 0x64,
-	0x12, 0x1e, 0x0a, 0x0a, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x53, 0x63, 0x68, 0x65,
-	0x6d, 0x61, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x53, 0x63, 0x68, 0x65,
-	0x6d, 0x61, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74,
-	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x34,
-	0x0a, 0x07, 0x45, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x45, 0x6e, 0x64,
-	0x44, 0x61, 0x74, 0x65, 0x22, 0xb4, 0x01, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x50, 0x6c,
-	0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x50, 0x68, 0x49,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x50, 0x68, 0x49, 0x64, 0x12, 0x18, 0x0a,
-	0x07, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
-	0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
-	0x49, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x50, 0x72, 0x6f, 0x64, 0x75,
-	0x63, 0x74, 0x49, 0x64, 0x73, 0x12, 0x24, 0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x50,
-	0x72, 0x6f, 0x6d, 0x6f, 0x52, 0x05, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x22, 0xb3, 0x01, 0x0a, 0x0b,
-	0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x2d, 0x0a, 0x0a, 0x46,
-	0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0d, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x0a,
-	0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x2e, 0x0a, 0x0a, 0x50, 0x72,
-	0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
-	0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x52, 0x0a,
-	0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x45, 0x0a, 0x10, 0x50, 0x72,
-	0x6f, 0x6d, 0x6f, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x03,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x50,
-	0x72, 0x6f, 0x6d, 0x6f, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x52,
-	0x10, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65,
-	0x72, 0x22, 0x4f, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69,
-	0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x23, 0x0a,
-	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6d,
-	0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65,
-	0x6d, 0x73, 0x22, 0xfd, 0x01, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50,
-	0x72, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
-	0x12, 0x27, 0x0a, 0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
-	0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c,
-	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74,
-	0x61, 0x6c, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10,
-	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x44,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x09, 0x70, 0x72, 0x6f, 0x63, 0x65,
-	0x73, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x69, 0x6e,
-	0x64, 0x62, 0x6f, 0x78, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c,
-	0x52, 0x09, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x06, 0x66,
-	0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6d, 0x69,
-	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c,
-	0x65, 0x64, 0x22, 0x9c, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x74,
-	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x6d,
-	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
-	0x73, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x2e, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
-	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e,
-	0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x52, 0x0a, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0xb0, 0x01, 0x0a, 0x0e, 0x4d,
-	0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5a, 0x0a,
-	0x11, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x49, 0x6e,
-	0x66, 0x6f, 0x12, 0x21, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x47, 0x65, 0x74,
-	0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e,
-	0x47, 0x65, 0x74, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x49, 0x6e, 0x66,
-	0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x11, 0x47, 0x65, 0x74,
-	0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e,
-	0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1d,
-	0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x6d,
-	0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x25, 0x5a,
-	0x23, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x61, 0x64, 0x61, 0x70, 0x74, 0x65,
-	0x72, 0x73, 0x2f, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x3b,
-	0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-})
+const file_mindbox_proto_rawDesc = "" +
+	"\n" +
+	"\rmindbox.proto\x12\amindbox\x1a\x1fgoogle/protobuf/timestamp.proto\"X\n" +
+	"\x05Money\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x14\n" +
+	"\x05nanos\x18\x03 \x01(\x05R\x05nanos\"l\n" +
+	"\x04Item\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\x12/\n" +
+	"\vprice_money\x18\x03 \x01(\v2\x0e.mindbox.MoneyR\n" +
+	"priceMoney\"\xd7\x01\n" +
+	"\n" +
+	"FailedItem\x12!\n" +
+	"\x04item\x18\x01 \x01(\v2\r.mindbox.ItemR\x04item\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vhttp_status\x18\x03 \x01(\x05R\n" +
+	"httpStatus\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12%\n" +
+	"\x0emindbox_status\x18\x05 \x01(\tR\rmindboxStatus\x12\x1c\n" +
+	"\tretryable\x18\x06 \x01(\bR\tretryable\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"_\n" +
+	"\fRejectedItem\x12!\n" +
+	"\x04item\x18\x01 \x01(\v2\r.mindbox.ItemR\x04item\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"A\n" +
+	"\vBalanceType\x12\x1e\n" +
+	"\n" +
+	"SystemName\x18\x01 \x01(\tR\n" +
+	"SystemName\x12\x12\n" +
+	"\x04Name\x18\x02 \x01(\tR\x04Name\"\x8f\x03\n" +
+	"\x05Promo\x12\x0e\n" +
+	"\x02Id\x18\x01 \x01(\x05R\x02Id\x12\x1e\n" +
+	"\n" +
+	"ExternalId\x18\x02 \x01(\tR\n" +
+	"ExternalId\x12\x12\n" +
+	"\x04Type\x18\x03 \x01(\tR\x04Type\x12\x12\n" +
+	"\x04Name\x18\x04 \x01(\tR\x04Name\x12\x1a\n" +
+	"\bSchemaId\x18\x05 \x01(\tR\bSchemaId\x128\n" +
+	"\tStartDate\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tStartDate\x124\n" +
+	"\aEndDate\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aEndDate\x12\x16\n" +
+	"\x06Amount\x18\b \x01(\x01R\x06Amount\x12 \n" +
+	"\vGroupingKey\x18\t \x01(\tR\vGroupingKey\x126\n" +
+	"\vBalanceType\x18\n" +
+	" \x01(\v2\x14.mindbox.BalanceTypeR\vBalanceType\x120\n" +
+	"\vAmountMoney\x18\v \x01(\v2\x0e.mindbox.MoneyR\vAmountMoney\"\x92\x01\n" +
+	"\x0eBalanceAccrual\x126\n" +
+	"\vBalanceType\x18\x01 \x01(\v2\x14.mindbox.BalanceTypeR\vBalanceType\x12\x16\n" +
+	"\x06Amount\x18\x02 \x01(\x01R\x06Amount\x120\n" +
+	"\vAmountMoney\x18\x03 \x01(\v2\x0e.mindbox.MoneyR\vAmountMoney\"\xb4\x01\n" +
+	"\x10PromoPlaceholder\x12\x12\n" +
+	"\x04PhId\x18\x01 \x01(\tR\x04PhId\x12\x18\n" +
+	"\aPromoId\x18\x02 \x01(\x05R\aPromoId\x12\x12\n" +
+	"\x04Type\x18\x03 \x01(\tR\x04Type\x12\x18\n" +
+	"\aMessage\x18\x04 \x01(\tR\aMessage\x12\x1e\n" +
+	"\n" +
+	"ProductIds\x18\x05 \x03(\tR\n" +
+	"ProductIds\x12$\n" +
+	"\x05Promo\x18\x06 \x01(\v2\x0e.mindbox.PromoR\x05Promo\"\xa8\x03\n" +
+	"\vImportModel\x12-\n" +
+	"\n" +
+	"FinalPrice\x18\x01 \x01(\v2\r.mindbox.ItemR\n" +
+	"FinalPrice\x12.\n" +
+	"\n" +
+	"Promotions\x18\x02 \x03(\v2\x0e.mindbox.PromoR\n" +
+	"Promotions\x12E\n" +
+	"\x10PromoPlaceholder\x18\x03 \x03(\v2\x19.mindbox.PromoPlaceholderR\x10PromoPlaceholder\x12\x16\n" +
+	"\x06Source\x18\x04 \x01(\tR\x06Source\x12\x1c\n" +
+	"\tBasePrice\x18\x05 \x01(\x01R\tBasePrice\x12\x1a\n" +
+	"\bDiscount\x18\x06 \x01(\x01R\bDiscount\x123\n" +
+	"\bAccruals\x18\a \x03(\v2\x17.mindbox.BalanceAccrualR\bAccruals\x126\n" +
+	"\x0eBasePriceMoney\x18\b \x01(\v2\x0e.mindbox.MoneyR\x0eBasePriceMoney\x124\n" +
+	"\rDiscountMoney\x18\t \x01(\v2\x0e.mindbox.MoneyR\rDiscountMoney\"\x88\x01\n" +
+	"\x18GetFinalPriceInfoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\x05items\x18\x02 \x03(\v2\r.mindbox.ItemR\x05items\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\bR\x05delta\x12!\n" +
+	"\fmobile_phone\x18\x04 \x01(\tR\vmobilePhone\"\xd3\x04\n" +
+	"\x19GetFinalPriceInfoResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0ftotal_processed\x18\x02 \x01(\x05R\x0etotalProcessed\x12!\n" +
+	"\ftotal_failed\x18\x03 \x01(\x05R\vtotalFailed\x12)\n" +
+	"\x10process_duration\x18\x04 \x01(\tR\x0fprocessDuration\x122\n" +
+	"\tprocessed\x18\x05 \x03(\v2\x14.mindbox.ImportModelR\tprocessed\x12%\n" +
+	"\x06failed\x18\x06 \x03(\v2\r.mindbox.ItemR\x06failed\x126\n" +
+	"\ffailed_items\x18\a \x03(\v2\x13.mindbox.FailedItemR\vfailedItems\x12\x1d\n" +
+	"\n" +
+	"cache_hits\x18\b \x01(\x05R\tcacheHits\x12!\n" +
+	"\fcache_misses\x18\t \x01(\x05R\vcacheMisses\x12#\n" +
+	"\rsnapshot_hits\x18\n" +
+	" \x01(\x05R\fsnapshotHits\x12%\n" +
+	"\x0etotal_rejected\x18\v \x01(\x05R\rtotalRejected\x121\n" +
+	"\brejected\x18\f \x03(\v2\x15.mindbox.RejectedItemR\brejected\x12\x1c\n" +
+	"\tcoalesced\x18\r \x01(\x05R\tcoalesced\x12=\n" +
+	"\fplaceholders\x18\x0e \x03(\v2\x19.mindbox.PromoPlaceholderR\fplaceholders\"\xf9\x02\n" +
+	"\x15FinalPriceInfoSummary\x12'\n" +
+	"\x0ftotal_processed\x18\x01 \x01(\x05R\x0etotalProcessed\x12!\n" +
+	"\ftotal_failed\x18\x02 \x01(\x05R\vtotalFailed\x12)\n" +
+	"\x10process_duration\x18\x03 \x01(\tR\x0fprocessDuration\x12\x1d\n" +
+	"\n" +
+	"cache_hits\x18\x04 \x01(\x05R\tcacheHits\x12!\n" +
+	"\fcache_misses\x18\x05 \x01(\x05R\vcacheMisses\x12#\n" +
+	"\rsnapshot_hits\x18\x06 \x01(\x05R\fsnapshotHits\x12%\n" +
+	"\x0etotal_rejected\x18\a \x01(\x05R\rtotalRejected\x12\x1c\n" +
+	"\tcoalesced\x18\b \x01(\x05R\tcoalesced\x12=\n" +
+	"\fplaceholders\x18\t \x03(\v2\x19.mindbox.PromoPlaceholderR\fplaceholders\"\xfe\x01\n" +
+	"\x13FinalPriceInfoChunk\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x122\n" +
+	"\tprocessed\x18\x02 \x03(\v2\x14.mindbox.ImportModelR\tprocessed\x126\n" +
+	"\ffailed_items\x18\x03 \x03(\v2\x13.mindbox.FailedItemR\vfailedItems\x128\n" +
+	"\asummary\x18\x04 \x01(\v2\x1e.mindbox.FinalPriceInfoSummaryR\asummary\x121\n" +
+	"\brejected\x18\x05 \x03(\v2\x15.mindbox.RejectedItemR\brejected\"\x9c\x01\n" +
+	"\x14GetPromoInfoResponse\x12)\n" +
+	"\x10total_promotions\x18\x01 \x01(\x05R\x0ftotalPromotions\x12)\n" +
+	"\x10process_duration\x18\x02 \x01(\tR\x0fprocessDuration\x12.\n" +
+	"\n" +
+	"Promotions\x18\x03 \x03(\v2\x0e.mindbox.PromoR\n" +
+	"Promotions\"\a\n" +
+	"\x05Empty2\xc7\x03\n" +
+	"\x0eMindboxService\x12Z\n" +
+	"\x11GetFinalPriceInfo\x12!.mindbox.GetFinalPriceInfoRequest\x1a\".mindbox.GetFinalPriceInfoResponse\x12_\n" +
+	"\x14UploadFinalPriceInfo\x12!.mindbox.GetFinalPriceInfoRequest\x1a\".mindbox.GetFinalPriceInfoResponse(\x01\x12Y\n" +
+	"\x14StreamFinalPriceInfo\x12!.mindbox.GetFinalPriceInfoRequest\x1a\x1c.mindbox.FinalPriceInfoChunk0\x01\x12Y\n" +
+	"\x12SyncFinalPriceInfo\x12!.mindbox.GetFinalPriceInfoRequest\x1a\x1c.mindbox.FinalPriceInfoChunk(\x010\x01\x12B\n" +
+	"\x11GetPromotionsInfo\x12\x0e.mindbox.Empty\x1a\x1d.mindbox.GetPromoInfoResponseB%Z#internal/adapters/inbound/grpc;grpcb\x06proto3"
 
 var (
 	file_mindbox_proto_rawDescOnce sync.Once
@@ -651,38 +1379,69 @@ func file_mindbox_proto_rawDescGZIP() []byte {
 	return file_mindbox_proto_rawDescData
 }
 
-var file_mindbox_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_mindbox_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_mindbox_proto_goTypes = []any{
-	(*Item)(nil),                      // 0: mindbox.Item
-	(*Promo)(nil),                     // 1: mindbox.Promo
-	(*PromoPlaceholder)(nil),          // 2: mindbox.PromoPlaceholder
-	(*ImportModel)(nil),               // 3: mindbox.ImportModel
-	(*GetFinalPriceInfoRequest)(nil),  // 4: mindbox.GetFinalPriceInfoRequest
-	(*GetFinalPriceInfoResponse)(nil), // 5: mindbox.GetFinalPriceInfoResponse
-	(*GetPromoInfoResponse)(nil),      // 6: mindbox.GetPromoInfoResponse
-	(*Empty)(nil),                     // 7: mindbox.Empty
-	(*timestamppb.Timestamp)(nil),     // 8: google.protobuf.Timestamp
+	(*Money)(nil),                     // 0: mindbox.Money
+	(*Item)(nil),                      // 1: mindbox.Item
+	(*FailedItem)(nil),                // 2: mindbox.FailedItem
+	(*RejectedItem)(nil),              // 3: mindbox.RejectedItem
+	(*BalanceType)(nil),               // 4: mindbox.BalanceType
+	(*Promo)(nil),                     // 5: mindbox.Promo
+	(*BalanceAccrual)(nil),            // 6: mindbox.BalanceAccrual
+	(*PromoPlaceholder)(nil),          // 7: mindbox.PromoPlaceholder
+	(*ImportModel)(nil),               // 8: mindbox.ImportModel
+	(*GetFinalPriceInfoRequest)(nil),  // 9: mindbox.GetFinalPriceInfoRequest
+	(*GetFinalPriceInfoResponse)(nil), // 10: mindbox.GetFinalPriceInfoResponse
+	(*FinalPriceInfoSummary)(nil),     // 11: mindbox.FinalPriceInfoSummary
+	(*FinalPriceInfoChunk)(nil),       // 12: mindbox.FinalPriceInfoChunk
+	(*GetPromoInfoResponse)(nil),      // 13: mindbox.GetPromoInfoResponse
+	(*Empty)(nil),                     // 14: mindbox.Empty
+	(*timestamppb.Timestamp)(nil),     // 15: google.protobuf.Timestamp
 }
 var file_mindbox_proto_depIdxs = []int32{
-	8,  // 0: mindbox.Promo.StartDate:type_name -> google.protobuf.Timestamp
-	8,  // 1: mindbox.Promo.EndDate:type_name -> google.protobuf.Timestamp
-	1,  // 2: mindbox.PromoPlaceholder.Promo:type_name -> mindbox.Promo
-	0,  // 3: mindbox.ImportModel.FinalPrice:type_name -> mindbox.Item
-	1,  // 4: mindbox.ImportModel.Promotions:type_name -> mindbox.Promo
-	2,  // 5: mindbox.ImportModel.PromoPlaceholder:type_name -> mindbox.PromoPlaceholder
-	0,  // 6: mindbox.GetFinalPriceInfoRequest.items:type_name -> mindbox.Item
-	3,  // 7: mindbox.GetFinalPriceInfoResponse.processed:type_name -> mindbox.ImportModel
-	0,  // 8: mindbox.GetFinalPriceInfoResponse.failed:type_name -> mindbox.Item
-	1,  // 9: mindbox.GetPromoInfoResponse.Promotions:type_name -> mindbox.Promo
-	4,  // 10: mindbox.MindboxService.GetFinalPriceInfo:input_type -> mindbox.GetFinalPriceInfoRequest
-	7,  // 11: mindbox.MindboxService.GetPromotionsInfo:input_type -> mindbox.Empty
-	5,  // 12: mindbox.MindboxService.GetFinalPriceInfo:output_type -> mindbox.GetFinalPriceInfoResponse
-	6,  // 13: mindbox.MindboxService.GetPromotionsInfo:output_type -> mindbox.GetPromoInfoResponse
-	12, // [12:14] is the sub-list for method output_type
-	10, // [10:12] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	0,  // 0: mindbox.Item.price_money:type_name -> mindbox.Money
+	1,  // 1: mindbox.FailedItem.item:type_name -> mindbox.Item
+	1,  // 2: mindbox.RejectedItem.item:type_name -> mindbox.Item
+	15, // 3: mindbox.Promo.StartDate:type_name -> google.protobuf.Timestamp
+	15, // 4: mindbox.Promo.EndDate:type_name -> google.protobuf.Timestamp
+	4,  // 5: mindbox.Promo.BalanceType:type_name -> mindbox.BalanceType
+	0,  // 6: mindbox.Promo.AmountMoney:type_name -> mindbox.Money
+	4,  // 7: mindbox.BalanceAccrual.BalanceType:type_name -> mindbox.BalanceType
+	0,  // 8: mindbox.BalanceAccrual.AmountMoney:type_name -> mindbox.Money
+	5,  // 9: mindbox.PromoPlaceholder.Promo:type_name -> mindbox.Promo
+	1,  // 10: mindbox.ImportModel.FinalPrice:type_name -> mindbox.Item
+	5,  // 11: mindbox.ImportModel.Promotions:type_name -> mindbox.Promo
+	7,  // 12: mindbox.ImportModel.PromoPlaceholder:type_name -> mindbox.PromoPlaceholder
+	6,  // 13: mindbox.ImportModel.Accruals:type_name -> mindbox.BalanceAccrual
+	0,  // 14: mindbox.ImportModel.BasePriceMoney:type_name -> mindbox.Money
+	0,  // 15: mindbox.ImportModel.DiscountMoney:type_name -> mindbox.Money
+	1,  // 16: mindbox.GetFinalPriceInfoRequest.items:type_name -> mindbox.Item
+	8,  // 17: mindbox.GetFinalPriceInfoResponse.processed:type_name -> mindbox.ImportModel
+	1,  // 18: mindbox.GetFinalPriceInfoResponse.failed:type_name -> mindbox.Item
+	2,  // 19: mindbox.GetFinalPriceInfoResponse.failed_items:type_name -> mindbox.FailedItem
+	3,  // 20: mindbox.GetFinalPriceInfoResponse.rejected:type_name -> mindbox.RejectedItem
+	7,  // 21: mindbox.GetFinalPriceInfoResponse.placeholders:type_name -> mindbox.PromoPlaceholder
+	7,  // 22: mindbox.FinalPriceInfoSummary.placeholders:type_name -> mindbox.PromoPlaceholder
+	8,  // 23: mindbox.FinalPriceInfoChunk.processed:type_name -> mindbox.ImportModel
+	2,  // 24: mindbox.FinalPriceInfoChunk.failed_items:type_name -> mindbox.FailedItem
+	11, // 25: mindbox.FinalPriceInfoChunk.summary:type_name -> mindbox.FinalPriceInfoSummary
+	3,  // 26: mindbox.FinalPriceInfoChunk.rejected:type_name -> mindbox.RejectedItem
+	5,  // 27: mindbox.GetPromoInfoResponse.Promotions:type_name -> mindbox.Promo
+	9,  // 28: mindbox.MindboxService.GetFinalPriceInfo:input_type -> mindbox.GetFinalPriceInfoRequest
+	9,  // 29: mindbox.MindboxService.UploadFinalPriceInfo:input_type -> mindbox.GetFinalPriceInfoRequest
+	9,  // 30: mindbox.MindboxService.StreamFinalPriceInfo:input_type -> mindbox.GetFinalPriceInfoRequest
+	9,  // 31: mindbox.MindboxService.SyncFinalPriceInfo:input_type -> mindbox.GetFinalPriceInfoRequest
+	14, // 32: mindbox.MindboxService.GetPromotionsInfo:input_type -> mindbox.Empty
+	10, // 33: mindbox.MindboxService.GetFinalPriceInfo:output_type -> mindbox.GetFinalPriceInfoResponse
+	10, // 34: mindbox.MindboxService.UploadFinalPriceInfo:output_type -> mindbox.GetFinalPriceInfoResponse
+	12, // 35: mindbox.MindboxService.StreamFinalPriceInfo:output_type -> mindbox.FinalPriceInfoChunk
+	12, // 36: mindbox.MindboxService.SyncFinalPriceInfo:output_type -> mindbox.FinalPriceInfoChunk
+	13, // 37: mindbox.MindboxService.GetPromotionsInfo:output_type -> mindbox.GetPromoInfoResponse
+	33, // [33:38] is the sub-list for method output_type
+	28, // [28:33] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_mindbox_proto_init() }
@@ -696,7 +1455,7 @@ func file_mindbox_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mindbox_proto_rawDesc), len(file_mindbox_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
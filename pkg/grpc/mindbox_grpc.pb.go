@@ -19,8 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MindboxService_GetFinalPriceInfo_FullMethodName = "/mindbox.MindboxService/GetFinalPriceInfo"
-	MindboxService_GetPromotionsInfo_FullMethodName = "/mindbox.MindboxService/GetPromotionsInfo"
+	MindboxService_GetFinalPriceInfo_FullMethodName    = "/mindbox.MindboxService/GetFinalPriceInfo"
+	MindboxService_UploadFinalPriceInfo_FullMethodName = "/mindbox.MindboxService/UploadFinalPriceInfo"
+	MindboxService_StreamFinalPriceInfo_FullMethodName = "/mindbox.MindboxService/StreamFinalPriceInfo"
+	MindboxService_SyncFinalPriceInfo_FullMethodName   = "/mindbox.MindboxService/SyncFinalPriceInfo"
+	MindboxService_GetPromotionsInfo_FullMethodName    = "/mindbox.MindboxService/GetPromotionsInfo"
 )
 
 // MindboxServiceClient is the client API for MindboxService service.
@@ -28,6 +31,13 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MindboxServiceClient interface {
 	GetFinalPriceInfo(ctx context.Context, in *GetFinalPriceInfoRequest, opts ...grpc.CallOption) (*GetFinalPriceInfoResponse, error)
+	// Items are uploaded in chunks; the id of the first chunk is used.
+	UploadFinalPriceInfo(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse], error)
+	// Results are sent batch by batch; the last message carries the summary.
+	StreamFinalPriceInfo(ctx context.Context, in *GetFinalPriceInfoRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FinalPriceInfoChunk], error)
+	// Every uploaded chunk is priced as soon as it arrives and its results are
+	// streamed back; the last message carries the summary for all chunks.
+	SyncFinalPriceInfo(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetFinalPriceInfoRequest, FinalPriceInfoChunk], error)
 	GetPromotionsInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetPromoInfoResponse, error)
 }
 
@@ -49,6 +59,51 @@ func (c *mindboxServiceClient) GetFinalPriceInfo(ctx context.Context, in *GetFin
 	return out, nil
 }
 
+func (c *mindboxServiceClient) UploadFinalPriceInfo(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MindboxService_ServiceDesc.Streams[0], MindboxService_UploadFinalPriceInfo_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_UploadFinalPriceInfoClient = grpc.ClientStreamingClient[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]
+
+func (c *mindboxServiceClient) StreamFinalPriceInfo(ctx context.Context, in *GetFinalPriceInfoRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FinalPriceInfoChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MindboxService_ServiceDesc.Streams[1], MindboxService_StreamFinalPriceInfo_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetFinalPriceInfoRequest, FinalPriceInfoChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_StreamFinalPriceInfoClient = grpc.ServerStreamingClient[FinalPriceInfoChunk]
+
+func (c *mindboxServiceClient) SyncFinalPriceInfo(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetFinalPriceInfoRequest, FinalPriceInfoChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MindboxService_ServiceDesc.Streams[2], MindboxService_SyncFinalPriceInfo_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetFinalPriceInfoRequest, FinalPriceInfoChunk]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_SyncFinalPriceInfoClient = grpc.BidiStreamingClient[GetFinalPriceInfoRequest, FinalPriceInfoChunk]
+
 func (c *mindboxServiceClient) GetPromotionsInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetPromoInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetPromoInfoResponse)
@@ -64,6 +119,13 @@ func (c *mindboxServiceClient) GetPromotionsInfo(ctx context.Context, in *Empty,
 // for forward compatibility.
 type MindboxServiceServer interface {
 	GetFinalPriceInfo(context.Context, *GetFinalPriceInfoRequest) (*GetFinalPriceInfoResponse, error)
+	// Items are uploaded in chunks; the id of the first chunk is used.
+	UploadFinalPriceInfo(grpc.ClientStreamingServer[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]) error
+	// Results are sent batch by batch; the last message carries the summary.
+	StreamFinalPriceInfo(*GetFinalPriceInfoRequest, grpc.ServerStreamingServer[FinalPriceInfoChunk]) error
+	// Every uploaded chunk is priced as soon as it arrives and its results are
+	// streamed back; the last message carries the summary for all chunks.
+	SyncFinalPriceInfo(grpc.BidiStreamingServer[GetFinalPriceInfoRequest, FinalPriceInfoChunk]) error
 	GetPromotionsInfo(context.Context, *Empty) (*GetPromoInfoResponse, error)
 	mustEmbedUnimplementedMindboxServiceServer()
 }
@@ -78,6 +140,15 @@ type UnimplementedMindboxServiceServer struct{}
 func (UnimplementedMindboxServiceServer) GetFinalPriceInfo(context.Context, *GetFinalPriceInfoRequest) (*GetFinalPriceInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFinalPriceInfo not implemented")
 }
+func (UnimplementedMindboxServiceServer) UploadFinalPriceInfo(grpc.ClientStreamingServer[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFinalPriceInfo not implemented")
+}
+func (UnimplementedMindboxServiceServer) StreamFinalPriceInfo(*GetFinalPriceInfoRequest, grpc.ServerStreamingServer[FinalPriceInfoChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFinalPriceInfo not implemented")
+}
+func (UnimplementedMindboxServiceServer) SyncFinalPriceInfo(grpc.BidiStreamingServer[GetFinalPriceInfoRequest, FinalPriceInfoChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method SyncFinalPriceInfo not implemented")
+}
 func (UnimplementedMindboxServiceServer) GetPromotionsInfo(context.Context, *Empty) (*GetPromoInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPromotionsInfo not implemented")
 }
@@ -120,6 +191,31 @@ func _MindboxService_GetFinalPriceInfo_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MindboxService_UploadFinalPriceInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MindboxServiceServer).UploadFinalPriceInfo(&grpc.GenericServerStream[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_UploadFinalPriceInfoServer = grpc.ClientStreamingServer[GetFinalPriceInfoRequest, GetFinalPriceInfoResponse]
+
+func _MindboxService_StreamFinalPriceInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetFinalPriceInfoRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MindboxServiceServer).StreamFinalPriceInfo(m, &grpc.GenericServerStream[GetFinalPriceInfoRequest, FinalPriceInfoChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_StreamFinalPriceInfoServer = grpc.ServerStreamingServer[FinalPriceInfoChunk]
+
+func _MindboxService_SyncFinalPriceInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MindboxServiceServer).SyncFinalPriceInfo(&grpc.GenericServerStream[GetFinalPriceInfoRequest, FinalPriceInfoChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MindboxService_SyncFinalPriceInfoServer = grpc.BidiStreamingServer[GetFinalPriceInfoRequest, FinalPriceInfoChunk]
+
 func _MindboxService_GetPromotionsInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -154,6 +250,23 @@ var MindboxService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _MindboxService_GetPromotionsInfo_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadFinalPriceInfo",
+			Handler:       _MindboxService_UploadFinalPriceInfo_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamFinalPriceInfo",
+			Handler:       _MindboxService_StreamFinalPriceInfo_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SyncFinalPriceInfo",
+			Handler:       _MindboxService_SyncFinalPriceInfo_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "mindbox.proto",
 }
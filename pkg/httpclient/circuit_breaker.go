@@ -1,43 +1,201 @@
 package httpclient
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"net/http"
 	"sync"
 	"time"
 )
 
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type CircuitBreakerSettings struct {
+	// MaxFailures is the number of consecutive failures that opens the
+	// breaker.
+	MaxFailures int
+	// ResetTimeout is how long the breaker stays open before it lets probes
+	// through.
+	ResetTimeout time.Duration
+	// HalfOpenProbes is how many calls may run at once while half-open. The
+	// breaker closes after that many probes succeed.
+	HalfOpenProbes int
+	// IsFailure decides which errors count against the breaker. It defaults
+	// to IsServerFailure.
+	IsFailure func(err error) bool
+	// OnStateChange is called after every transition, outside of the lock.
+	OnStateChange func(from, to State)
+}
+
+// CircuitBreaker only holds its lock to update counters, so calls in the
+// closed state run concurrently.
 type CircuitBreaker struct {
-	mu           sync.Mutex
-	failures     int
-	maxFailures  int
-	resetTimeout time.Duration
-	lastFailure  time.Time
+	settings CircuitBreakerSettings
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	failures   int
+	probes     int
+	successes  int
+	openedAt   time.Time
 }
 
 func NewCircuitBreaker(maxRetries int, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		maxFailures:  maxRetries,
-		resetTimeout: resetTimeout,
+	return NewCircuitBreakerWithSettings(CircuitBreakerSettings{
+		MaxFailures:  maxRetries,
+		ResetTimeout: resetTimeout,
+	})
+}
+
+func NewCircuitBreakerWithSettings(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.MaxFailures < 1 {
+		settings.MaxFailures = 1
+	}
+	if settings.HalfOpenProbes < 1 {
+		settings.HalfOpenProbes = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = IsServerFailure
 	}
+	return &CircuitBreaker{settings: settings}
 }
 
-func (cb *CircuitBreaker) Execute(f func() error) error {
+// IsServerFailure counts transport errors, 5xx and 429 responses. Client
+// errors and cancellation by the caller say nothing about the health of the
+// remote side.
+func IsServerFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func (cb *CircuitBreaker) State() State {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	if cb.failures > 0 && time.Since(cb.lastFailure) > cb.resetTimeout {
-		cb.failures = 0
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= cb.settings.ResetTimeout {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) Execute(f func() error) error {
+	generation, err := cb.before()
+	if err != nil {
+		return err
 	}
 
-	if cb.failures > cb.maxFailures {
-		return fmt.Errorf("circuit breaker open")
+	err = f()
+	cb.after(generation, err)
+	return err
+}
+
+func (cb *CircuitBreaker) before() (uint64, error) {
+	cb.mu.Lock()
+	var change func()
+	defer func() {
+		cb.mu.Unlock()
+		if change != nil {
+			change()
+		}
+	}()
+
+	if cb.state == StateOpen {
+		if time.Since(cb.openedAt) < cb.settings.ResetTimeout {
+			return 0, ErrCircuitOpen
+		}
+		change = cb.setState(StateHalfOpen)
+	}
+	if cb.state == StateHalfOpen {
+		if cb.probes >= cb.settings.HalfOpenProbes {
+			return 0, ErrCircuitOpen
+		}
+		cb.probes++
 	}
+	return cb.generation, nil
+}
 
-	err := f()
-	if err != nil {
+func (cb *CircuitBreaker) after(generation uint64, err error) {
+	cb.mu.Lock()
+	var change func()
+	defer func() {
+		cb.mu.Unlock()
+		if change != nil {
+			change()
+		}
+	}()
+
+	// The state changed while the call was running, so its outcome
+	// belongs to a period that is already over.
+	if generation != cb.generation {
+		return
+	}
+
+	failed := err != nil && cb.settings.IsFailure(err)
+	switch cb.state {
+	case StateClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
 		cb.failures++
-		cb.lastFailure = time.Now()
-		return err
+		if cb.failures >= cb.settings.MaxFailures {
+			change = cb.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		cb.probes--
+		if failed {
+			change = cb.setState(StateOpen)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.settings.HalfOpenProbes {
+			change = cb.setState(StateClosed)
+		}
+	}
+}
+
+// setState must be called with the lock held. It returns the callback to run
+// once the lock is released.
+func (cb *CircuitBreaker) setState(state State) func() {
+	from := cb.state
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+	if state == StateOpen {
+		cb.openedAt = time.Now()
+	}
+
+	if cb.settings.OnStateChange == nil || from == state {
+		return nil
 	}
-	return nil
+	return func() { cb.settings.OnStateChange(from, state) }
 }
@@ -18,7 +18,11 @@ type HTTPClient interface {
 type APIClient struct {
 	baseURL    *url.URL
 	httpClient HTTPClient
-	circuit    *CircuitBreaker
+	// probeClient sends Ping requests without retries.
+	probeClient *http.Client
+	circuit     *CircuitBreaker
+	limiter     *RateLimiter
+	metrics     *Metrics
 }
 
 type APIError struct {
@@ -53,7 +57,25 @@ type OptionsSt struct {
 
 	RetryCount         int
 	RetryInterval      time.Duration
+	RetryMaxInterval   time.Duration
 	InsecureSkipVerify bool
+
+	// RetryPolicy replaces the exponential backoff built from the settings
+	// above.
+	RetryPolicy RetryPolicy
+	// RetryBudget is the number of retries that may be spent in a row;
+	// RetryBudgetRatio of a retry is earned back by every request that
+	// needs none. Zero disables the budget.
+	RetryBudget      int
+	RetryBudgetRatio float64
+
+	// Metrics records request latency, retries and the circuit state; nil
+	// disables it.
+	Metrics *Metrics
+
+	// RequestsPerSecond limits outgoing requests; zero disables the limit.
+	RequestsPerSecond float64
+	RequestsBurst     int
 }
 
 func (o *OptionsSt) Normalize() {
@@ -66,6 +88,16 @@ func (o *OptionsSt) Normalize() {
 	if o.RetryInterval == 0 {
 		o.RetryInterval = 1 * time.Second
 	}
+	if o.RetryMaxInterval < o.RetryInterval {
+		o.RetryMaxInterval = max(30*time.Second, o.RetryInterval)
+	}
+	if o.RetryPolicy == nil {
+		o.RetryPolicy = ExponentialBackoff{
+			MaxRetries: o.RetryCount,
+			Initial:    o.RetryInterval,
+			Max:        o.RetryMaxInterval,
+		}
+	}
 }
 
 func NewAPIClient(baseURL string, opts *OptionsSt, cb *CircuitBreaker) (*APIClient, error) {
@@ -85,15 +117,31 @@ func NewAPIClient(baseURL string, opts *OptionsSt, cb *CircuitBreaker) (*APIClie
 		Transport: transport,
 	}
 
+	limiter := NewRateLimiter(opts.RequestsPerSecond, opts.RequestsBurst)
+	retry := NewRetryDecorator(client, opts.RetryPolicy, NewRetryBudget(opts.RetryBudget, opts.RetryBudgetRatio))
+	retry.metrics = opts.Metrics
+	retry.limiter = limiter
+	opts.Metrics.trackCircuit(cb)
+
 	return &APIClient{
-		baseURL:    u,
-		httpClient: NewRetryDecorator(client, opts.RetryCount, opts.RetryInterval),
-		circuit:    cb,
+		baseURL:     u,
+		httpClient:  retry,
+		probeClient: client,
+		circuit:     cb,
+		limiter:     limiter,
+		metrics:     opts.Metrics,
 	}, nil
 }
 
 func (c *APIClient) Execute(ctx context.Context, req *http.Request, v any) error {
-	return c.circuit.Execute(func() error {
+	if err := waitAttempt(ctx, c.limiter); err != nil {
+		return err
+	}
+	start := time.Now()
+	if t := traceFromContext(ctx); t != nil {
+		defer func() { t.add(time.Since(start)) }()
+	}
+	err := c.circuit.Execute(func() error {
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
 			return fmt.Errorf("request failed: %w", err)
@@ -111,4 +159,29 @@ func (c *APIClient) Execute(ctx context.Context, req *http.Request, v any) error
 		}
 		return nil
 	})
+	c.metrics.observeRequest(operationFromContext(req.Context(), req.Method), time.Since(start).Seconds(), err)
+	return err
+}
+
+// Ping checks that the API answers at its base URL. Any response below 500
+// counts, since APIs commonly reject unauthenticated requests to their root.
+// Pings bypass the rate limiter, retries and the circuit breaker.
+func (c *APIClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.probeClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return &APIError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+func (c *APIClient) CircuitState() State {
+	return c.circuit.State()
 }
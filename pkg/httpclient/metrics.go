@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type operationKey struct{}
+
+// WithOperation names the requests made with ctx in metrics. Requests
+// without a name are reported under their method.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+func operationFromContext(ctx context.Context, method string) string {
+	if op, ok := ctx.Value(operationKey{}).(string); ok && op != "" {
+		return op
+	}
+	return method
+}
+
+// Metrics holds the APIClient instruments. A nil *Metrics records nothing.
+type Metrics struct {
+	reg             prometheus.Registerer
+	namespace       string
+	requestDuration *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+}
+
+// NewMetrics registers the client instruments with reg. namespace prefixes
+// every metric name, so several clients can share one registry.
+func NewMetrics(reg prometheus.Registerer, namespace string) *Metrics {
+	m := &Metrics{
+		reg:       reg,
+		namespace: namespace,
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of API calls by operation and result, including retries.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"operation", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Retried API requests by operation.",
+		}, []string{"operation"}),
+	}
+	reg.MustRegister(m.requestDuration, m.retries)
+	return m
+}
+
+// trackCircuit exports the state of cb: 0 closed, 1 open, 2 half-open.
+func (m *Metrics) trackCircuit(cb *CircuitBreaker) {
+	if m == nil || cb == nil {
+		return
+	}
+	m.reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state: 0 closed, 1 open, 2 half-open.",
+	}, func() float64 {
+		return float64(cb.State())
+	}))
+}
+
+func (m *Metrics) observeRequest(operation string, seconds float64, err error) {
+	if m == nil {
+		return
+	}
+	m.requestDuration.WithLabelValues(operation, resultCode(err)).Observe(seconds)
+}
+
+func (m *Metrics) observeRetry(operation string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(operation).Inc()
+}
+
+func resultCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return strconv.Itoa(apiErr.StatusCode)
+	}
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "error"
+}
@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket. A caller may take more tokens than the
+// bucket holds; the bucket then goes into debt and later callers wait until
+// it is paid back, so large batches are throttled instead of rejected.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns nil when rate is not positive. A nil limiter never
+// blocks.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+type rateLimitKey struct{}
+
+type rateLimit struct {
+	limiter *RateLimiter
+	n       int
+}
+
+// WithRateLimit makes every attempt of the requests made with ctx, retries
+// included, take n tokens of limiter on top of the request limit of the
+// client.
+func WithRateLimit(ctx context.Context, limiter *RateLimiter, n int) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, rateLimit{limiter: limiter, n: n})
+}
+
+// waitAttempt takes the tokens one attempt of a request made with ctx needs:
+// one of limiter and the ones set by WithRateLimit.
+func waitAttempt(ctx context.Context, limiter *RateLimiter) error {
+	if err := limiter.Wait(ctx, 1); err != nil {
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+	if limit, ok := ctx.Value(rateLimitKey{}).(rateLimit); ok {
+		if err := limit.limiter.Wait(ctx, limit.n); err != nil {
+			return fmt.Errorf("rate limit wait: %w", err)
+		}
+	}
+	return nil
+}
@@ -18,6 +18,9 @@ type RequestBuilder struct {
 	query    url.Values
 	headers  http.Header
 	body     io.Reader
+
+	idempotent bool
+	operation  string
 }
 
 func (c *APIClient) NewRequest(method, endpoint string) *RequestBuilder {
@@ -41,6 +44,18 @@ func (b *RequestBuilder) WithContext(ctx context.Context) *RequestBuilder {
 	return b
 }
 
+// Idempotent allows the request to be retried whatever its method is.
+func (b *RequestBuilder) Idempotent() *RequestBuilder {
+	b.idempotent = true
+	return b
+}
+
+// WithOperation names the request in metrics.
+func (b *RequestBuilder) WithOperation(operation string) *RequestBuilder {
+	b.operation = operation
+	return b
+}
+
 func (b *RequestBuilder) WithQueryParam(key, value string) *RequestBuilder {
 	b.query.Add(key, value)
 	return b
@@ -53,12 +68,27 @@ func (b *RequestBuilder) WithJSONBody(body any) *RequestBuilder {
 	return b
 }
 
+// Build resolves the endpoint against the base URL. An absolute endpoint,
+// such as a download link returned by the API, is used as is.
 func (b *RequestBuilder) Build() (*http.Request, error) {
 	u := *b.baseURL
-	u.Path = path.Join(u.Path, b.endpoint)
-	u.RawQuery = b.query.Encode()
+	if abs, err := url.Parse(b.endpoint); err == nil && abs.IsAbs() {
+		u = *abs
+	} else {
+		u.Path = path.Join(u.Path, b.endpoint)
+	}
+	if len(b.query) > 0 {
+		u.RawQuery = b.query.Encode()
+	}
 
-	req, err := http.NewRequestWithContext(b.ctx, b.method, u.String(), b.body)
+	ctx := b.ctx
+	if b.idempotent {
+		ctx = WithIdempotent(ctx)
+	}
+	if b.operation != "" {
+		ctx = WithOperation(ctx, b.operation)
+	}
+	req, err := http.NewRequestWithContext(ctx, b.method, u.String(), b.body)
 	if err != nil {
 		return nil, err
 	}
@@ -1,45 +1,101 @@
 package httpclient
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"time"
 )
 
+type idempotentKey struct{}
+
+// WithIdempotent marks requests made with ctx as safe to retry even though
+// their method is not idempotent.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
 type RetryDecorator struct {
-	client     HTTPClient
-	maxRetries int
-	backoff    time.Duration
+	client  HTTPClient
+	policy  RetryPolicy
+	budget  *RetryBudget
+	metrics *Metrics
+	// limiter is waited on before every retry, like APIClient does before
+	// the first attempt, so retries stay within the same rate limit.
+	limiter *RateLimiter
 }
 
-func NewRetryDecorator(client HTTPClient, maxRetries int, backoff time.Duration) *RetryDecorator {
+// NewRetryDecorator retries requests according to policy. A nil budget
+// doesn't limit retries.
+func NewRetryDecorator(client HTTPClient, policy RetryPolicy, budget *RetryBudget) *RetryDecorator {
 	return &RetryDecorator{
-		client:     client,
-		maxRetries: maxRetries,
-		backoff:    backoff,
+		client: client,
+		policy: policy,
+		budget: budget,
 	}
 }
 
-func (c *RetryDecorator) Do(req *http.Request) (resp *http.Response, err error) {
-	getBody := req.GetBody
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 && getBody != nil {
-			req.Body, _ = getBody()
+// Do retries only requests that are idempotent or marked with WithIdempotent,
+// and gives up as soon as the request context is done.
+func (c *RetryDecorator) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req) && (req.Body == nil || req.GetBody != nil)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.client.Do(req)
+		if !retryable || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay, ok := c.policy.Backoff(attempt, resp, err)
+		if !ok {
+			if attempt == 1 && err == nil {
+				c.budget.deposit()
+			}
+			return resp, err
+		}
+		if deadline, has := ctx.Deadline(); has && time.Until(deadline) < delay {
+			return resp, err
+		}
+		if !c.budget.withdraw() {
+			return resp, err
 		}
 
-		resp, err = c.client.Do(req)
-		if shouldRetry(err, resp) {
-			time.Sleep(c.backoff)
-			c.backoff *= 2
-			continue
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if err := waitAttempt(ctx, c.limiter); err != nil {
+			return nil, err
+		}
+		c.metrics.observeRetry(operationFromContext(ctx, req.Method))
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
 		}
-		break
 	}
-	return
 }
 
-func shouldRetry(err error, resp *http.Response) bool {
-	if err != nil {
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
 		return true
 	}
-	return resp.StatusCode >= 500
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
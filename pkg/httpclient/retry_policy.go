@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides whether a failed attempt is retried and how long to
+// wait before the next one. attempt starts at 1 for the first retry. resp is
+// nil when the request failed without a response.
+type RetryPolicy interface {
+	Backoff(attempt int, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff retries transport errors, 5xx and 429 responses. The
+// delay doubles with every attempt up to Max, and half of it is randomized so
+// that clients failing together don't retry together. A Retry-After header on
+// a 429 or 503 response takes precedence over the computed delay, but is
+// capped by Max too.
+type ExponentialBackoff struct {
+	MaxRetries int
+	Initial    time.Duration
+	Max        time.Duration
+}
+
+func (p ExponentialBackoff) Backoff(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt > p.MaxRetries || !isRetryable(resp, err) {
+		return 0, false
+	}
+	if d, ok := retryAfter(resp); ok {
+		if p.Max > 0 {
+			d = min(d, p.Max)
+		}
+		return d, true
+	}
+
+	d := p.Initial << (attempt - 1)
+	// The shift overflows after enough attempts.
+	if p.Initial > 0 && (d <= 0 || d>>(attempt-1) != p.Initial) {
+		d = math.MaxInt64
+	}
+	if p.Max > 0 {
+		d = min(d, p.Max)
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	half := d / 2
+	return half + rand.N(half+1), true
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, which
+// holds either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// RetryBudget caps retries to a share of the traffic. Every retry spends a
+// token and every request that needs no retry earns ratio of one, so a
+// struggling server sees at most about ratio extra requests per request once
+// the initial tokens are spent. A nil budget never runs out.
+type RetryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewRetryBudget returns nil when maxTokens is not positive.
+func NewRetryBudget(maxTokens int, ratio float64) *RetryBudget {
+	if maxTokens <= 0 {
+		return nil
+	}
+	return &RetryBudget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.tokens = min(b.maxTokens, b.tokens+b.ratio)
+	b.mu.Unlock()
+}
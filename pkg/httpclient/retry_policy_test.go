@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	failed := errors.New("connection reset")
+	tests := []struct {
+		name     string
+		policy   ExponentialBackoff
+		attempt  int
+		min, max time.Duration
+	}{
+		{"first", ExponentialBackoff{MaxRetries: 10, Initial: time.Second, Max: time.Minute}, 1, 500 * time.Millisecond, time.Second},
+		{"doubled", ExponentialBackoff{MaxRetries: 10, Initial: time.Second, Max: time.Minute}, 3, 2 * time.Second, 4 * time.Second},
+		{"capped", ExponentialBackoff{MaxRetries: 10, Initial: time.Second, Max: 5 * time.Second}, 5, 2500 * time.Millisecond, 5 * time.Second},
+		{"overflow capped", ExponentialBackoff{MaxRetries: 1000, Initial: time.Second, Max: time.Minute}, 100, 30 * time.Second, time.Minute},
+		{"overflow without max", ExponentialBackoff{MaxRetries: 1000, Initial: time.Second}, 40, 1 << 62, 1<<63 - 1},
+		{"shift past the width", ExponentialBackoff{MaxRetries: 1000, Initial: time.Second}, 100, 1 << 62, 1<<63 - 1},
+		{"no initial", ExponentialBackoff{MaxRetries: 10}, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				d, ok := tt.policy.Backoff(tt.attempt, nil, failed)
+				if !ok {
+					t.Fatalf("Backoff(%d) did not retry", tt.attempt)
+				}
+				if d < tt.min || d > tt.max {
+					t.Fatalf("Backoff(%d) = %s, want between %s and %s", tt.attempt, d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffStops(t *testing.T) {
+	policy := ExponentialBackoff{MaxRetries: 2, Initial: time.Second, Max: time.Minute}
+	if _, ok := policy.Backoff(3, nil, errors.New("timeout")); ok {
+		t.Error("retried after MaxRetries")
+	}
+	if _, ok := policy.Backoff(1, &http.Response{StatusCode: http.StatusBadRequest}, nil); ok {
+		t.Error("retried a 400 response")
+	}
+}
+
+func TestExponentialBackoffRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		max    time.Duration
+		want   time.Duration
+	}{
+		{"seconds", http.StatusTooManyRequests, "7", time.Minute, 7 * time.Second},
+		{"capped by max", http.StatusServiceUnavailable, "86400", time.Minute, time.Minute},
+		{"without max", http.StatusServiceUnavailable, "86400", 0, 24 * time.Hour},
+		{"negative", http.StatusTooManyRequests, "-5", time.Minute, 0},
+		{"date in the past", http.StatusTooManyRequests, "Mon, 01 Jan 2001 00:00:00 GMT", time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := ExponentialBackoff{MaxRetries: 3, Initial: time.Second, Max: tt.max}
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Retry-After": {tt.header}}}
+			d, ok := policy.Backoff(1, resp, nil)
+			if !ok || d != tt.want {
+				t.Errorf("Backoff = %s, %t, want %s, true", d, ok, tt.want)
+			}
+		})
+	}
+}
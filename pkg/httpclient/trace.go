@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type traceKey struct{}
+
+// Trace accumulates the time APIClient spends on requests made with a
+// context returned by WithTrace. Time spent waiting for the rate limiter
+// before the first attempt is not counted; waits before retries are, like
+// the backoff itself.
+type Trace struct {
+	mu       sync.Mutex
+	elapsed  time.Duration
+	requests int
+}
+
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+func (t *Trace) Elapsed() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.elapsed
+}
+
+func (t *Trace) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+func (t *Trace) add(d time.Duration) {
+	t.mu.Lock()
+	t.elapsed += d
+	t.requests++
+	t.mu.Unlock()
+}
+
+func traceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}